@@ -3,9 +3,8 @@ package main
 import (
 	"fmt"
 	"github.com/rookiecj/go-langext/httpx"
-	"google.golang.org/protobuf/proto"
+	"github.com/rookiecj/go-langext/httpx/protobuf"
 	"log"
-	pb "protobuf-example"
 	"protobuf-example/proto/dto"
 )
 
@@ -20,21 +19,19 @@ var testPostUrl = "http://0.0.0.0:8080/post"
 
 func makeRequest(request *dto.PostRequest) *dto.PostResponse {
 
-	c := httpx.NewClient(httpx.WithDefaultBodyParser("application/protobuf", pb.ProtoBufBodyParser))
+	c := httpx.NewClient(protobuf.WithDefaultBodyParsers())
 
-	req, err := proto.Marshal(request)
-	if err != nil {
-		log.Fatalf("Unable to marshal request : %v", err)
-	}
 	resp, err := c.Post(testPostUrl,
-		httpx.WithBytes("application/x-binary", req))
+		protobuf.WithMessage(request))
 	if err != nil {
 		log.Fatalf("Unable to read from the server : %v", err)
 	}
 	defer resp.Close()
 
 	respObj := &dto.PostResponse{}
-	err = resp.Unmarshal(respObj)
+	if err = resp.Unmarshal(respObj); err != nil {
+		log.Fatalf("Unable to parse the response : %v", err)
+	}
 	return respObj
 }
 
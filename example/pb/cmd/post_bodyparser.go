@@ -3,10 +3,10 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"github.com/rookiecj/go-langext/httpx/protobuf"
 	"google.golang.org/protobuf/proto"
 	"log"
 	"net/http"
-	pb "protobuf-example"
 	"protobuf-example/proto/dto"
 )
 
@@ -24,7 +24,9 @@ func makeRequest(request *dto.PostRequest) *dto.PostResponse {
 	defer resp.Body.Close()
 
 	respObj := &dto.PostResponse{}
-	pb.ProtoBufBodyParser(resp.Body, respObj)
+	if err = protobuf.BodyParser(resp.Body, respObj); err != nil {
+		log.Fatalf("Unable to parse the response : %v", err)
+	}
 	return respObj
 }
 
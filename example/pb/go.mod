@@ -6,5 +6,8 @@ require (
 	github.com/golang/protobuf v1.5.3
 	github.com/gorilla/mux v1.8.1
 	github.com/rookiecj/go-langext v0.4.0
-	google.golang.org/protobuf v1.32.0
+	google.golang.org/protobuf v1.33.0
 )
+
+// httpx/protobuf of this tree
+replace github.com/rookiecj/go-langext => ../..
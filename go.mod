@@ -1,3 +1,5 @@
 module github.com/rookiecj/go-langext
 
-go 1.19
+go 1.21
+
+require google.golang.org/protobuf v1.33.0
@@ -0,0 +1,221 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultEjectFailures = 5
+	DefaultEjectCooldown = 30 * time.Second
+)
+
+// BalancePolicy decides which endpoint a request is sent to
+type BalancePolicy int
+
+const (
+	// RoundRobin picks the endpoints in turn(default)
+	RoundRobin BalancePolicy = iota
+	// Random picks an endpoint at random
+	Random
+	// LeastOutstanding picks the endpoint with the fewest requests in flight
+	LeastOutstanding
+)
+
+// WithEndpoints sets the base urls relative urls of requests are balanced over
+// it takes the place of WithBaseURL, absolute urls are sent as they are
+func WithEndpoints(endpoints []string, policy BalancePolicy) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.endpoints = append([]string{}, endpoints...)
+		clientOptions.balancePolicy = policy
+	}
+}
+
+// WithEjection ejects an endpoint for cooldown after failures consecutive failures
+// a transport error or a status of 500 or more is a failure, not the cancellation of the caller
+// failures less than 1 disables ejection
+func WithEjection(failures int, cooldown time.Duration) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.ejectFailures = failures
+		clientOptions.ejectCooldown = cooldown
+	}
+}
+
+// WithRetries retries idempotent requests up to retries times
+// on a transport error or 502, 503, 504, on another endpoint if there is one
+// requests of other methods are retried with WithIdempotencyKey or WithIdempotency
+func WithRetries(retries int) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.retries = retries
+	}
+}
+
+// endpoint is a base url with its health
+type endpoint struct {
+	url          *url.URL
+	outstanding  int
+	failures     int
+	ejectedUntil time.Time
+}
+
+func (ep *endpoint) healthy(now time.Time) bool {
+	return !now.Before(ep.ejectedUntil)
+}
+
+type balancer struct {
+	mu          sync.Mutex
+	endpoints   []*endpoint
+	policy      BalancePolicy
+	next        int
+	maxFailures int
+	cooldown    time.Duration
+	rand        *rand.Rand
+	now         func() time.Time
+}
+
+func newBalancer(endpoints []string, policy BalancePolicy, maxFailures int, cooldown time.Duration) (*balancer, error) {
+	b := &balancer{
+		policy:      policy,
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:         time.Now,
+	}
+	for _, e := range endpoints {
+		u, err := url.Parse(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %s: %s", e, err)
+		}
+		b.endpoints = append(b.endpoints, &endpoint{url: u})
+	}
+	return b, nil
+}
+
+// pick chooses an endpoint and counts it outstanding until done
+// healthy endpoints not in tried are preferred, then healthy ones,
+// then ejected ones not in tried, then any
+func (b *balancer) pick(tried map[*endpoint]bool) *endpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	filters := []func(ep *endpoint) bool{
+		func(ep *endpoint) bool { return ep.healthy(now) && !tried[ep] },
+		func(ep *endpoint) bool { return ep.healthy(now) },
+		func(ep *endpoint) bool { return !tried[ep] },
+		func(ep *endpoint) bool { return true },
+	}
+	for _, filter := range filters {
+		var candidates []int
+		for i, ep := range b.endpoints {
+			if filter(ep) {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) != 0 {
+			ep := b.endpoints[b.choose(candidates)]
+			ep.outstanding++
+			return ep
+		}
+	}
+	return nil
+}
+
+// choose returns one of the candidate indexes by the policy
+func (b *balancer) choose(candidates []int) int {
+	switch b.policy {
+	case Random:
+		return candidates[b.rand.Intn(len(candidates))]
+	case LeastOutstanding:
+		least := candidates[0]
+		for _, i := range candidates[1:] {
+			if b.endpoints[i].outstanding < b.endpoints[least].outstanding {
+				least = i
+			}
+		}
+		return least
+	default:
+		// the first candidate at or after next
+		chosen := candidates[0]
+		for _, i := range candidates {
+			if i >= b.next {
+				chosen = i
+				break
+			}
+		}
+		b.next = (chosen + 1) % len(b.endpoints)
+		return chosen
+	}
+}
+
+// attemptResult is the result of a request to an endpoint
+type attemptResult int
+
+const (
+	attemptSucceeded attemptResult = iota
+	attemptFailed
+	// attemptCanceled is canceled by the caller, it tells nothing of the endpoint
+	attemptCanceled
+	// attemptNotSent failed before sending like an error of the signer
+	attemptNotSent
+)
+
+// resultOf returns the result of a request
+// a transport error or a status of 500 or more is a failure unless ctx is done or canceled
+func resultOf(ctx context.Context, resp *http.Response, err error) attemptResult {
+	switch {
+	case err != nil && (ctx.Err() != nil || errors.Is(err, context.Canceled)):
+		return attemptCanceled
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		return attemptFailed
+	}
+	return attemptSucceeded
+}
+
+// done reports the result of a request to the endpoint
+func (b *balancer) done(ep *endpoint, result attemptResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ep.outstanding--
+	switch result {
+	case attemptCanceled, attemptNotSent:
+		return
+	case attemptSucceeded:
+		ep.failures = 0
+		ep.ejectedUntil = time.Time{}
+		return
+	}
+	ep.failures++
+	if b.maxFailures > 0 && ep.failures >= b.maxFailures {
+		ep.ejectedUntil = b.now().Add(b.cooldown)
+		ep.failures = 0
+	}
+}
+
+// isIdempotent reports whether a request of the method can be sent again
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether the result of an attempt is worth another one
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startCountServer responds status and counts the requests
+func startCountServer(status int, count *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(count, 1)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestWithEndpoints(t *testing.T) {
+	var count1, count2, countBad int32
+	server1 := startCountServer(http.StatusOK, &count1)
+	defer server1.Close()
+	server2 := startCountServer(http.StatusOK, &count2)
+	defer server2.Close()
+	bad := startCountServer(http.StatusServiceUnavailable, &countBad)
+	defer bad.Close()
+
+	tests := []struct {
+		name      string
+		client    *Client
+		method    string
+		requests  int
+		want      []int32
+		wantCodes int
+	}{
+		{
+			name:      "round robin",
+			client:    NewClient(WithEndpoints([]string{server1.URL, server2.URL}, RoundRobin)),
+			method:    http.MethodGet,
+			requests:  4,
+			want:      []int32{2, 2, 0},
+			wantCodes: http.StatusOK,
+		},
+		{
+			name:      "least outstanding",
+			client:    NewClient(WithEndpoints([]string{server1.URL, server2.URL}, LeastOutstanding)),
+			method:    http.MethodGet,
+			requests:  2,
+			want:      []int32{2, 0, 0},
+			wantCodes: http.StatusOK,
+		},
+		{
+			name: "retry on another endpoint",
+			client: NewClient(WithEndpoints([]string{bad.URL, server1.URL}, RoundRobin),
+				WithRetries(1)),
+			method:    http.MethodGet,
+			requests:  2,
+			want:      []int32{2, 0, 2},
+			wantCodes: http.StatusOK,
+		},
+		{
+			name: "ejected endpoint",
+			client: NewClient(WithEndpoints([]string{bad.URL, server1.URL}, RoundRobin),
+				WithEjection(1, time.Minute)),
+			method:    http.MethodGet,
+			requests:  3,
+			want:      []int32{2, 0, 1},
+			wantCodes: http.StatusOK,
+		},
+		{
+			name: "post is not retried",
+			client: NewClient(WithEndpoints([]string{bad.URL, server1.URL}, RoundRobin),
+				WithRetries(1), WithEjection(0, 0)),
+			method:    http.MethodPost,
+			requests:  1,
+			want:      []int32{0, 0, 1},
+			wantCodes: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&count1, 0)
+			atomic.StoreInt32(&count2, 0)
+			atomic.StoreInt32(&countBad, 0)
+
+			var res *Response
+			var err error
+			for i := 0; i < tt.requests; i++ {
+				u, _ := url.Parse("/items")
+				if res, err = tt.client.Do(tt.method, u); err != nil {
+					t.Fatalf("Do() error = %v", err)
+				}
+				res.Close()
+			}
+			if res.StatusCode() != tt.wantCodes {
+				t.Errorf("Do() status got = %d, want %d", res.StatusCode(), tt.wantCodes)
+			}
+			got := []int32{atomic.LoadInt32(&count1), atomic.LoadInt32(&count2), atomic.LoadInt32(&countBad)}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Do() requests got = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestWithRetries_Body(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, 16)
+		n, _ := r.Body.Read(body)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(body[:n])
+	}))
+	defer server.Close()
+
+	res, err := NewClient(WithRetries(2)).Put(server.URL,
+		WithString("text/plain", "payload"),
+		WithBodyParser("text/plain", TextBodyParser))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	var got string
+	if err = res.Unmarshal(&got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got != "payload" || atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("Put() got = %v after %d attempts, want payload after 2", got, attempts)
+	}
+}
+
+func TestWithEndpoints_Invalid(t *testing.T) {
+	client := NewClient(WithEndpoints([]string{"http://[::1"}, Random))
+	if _, err := client.Get("/items"); err == nil {
+		t.Errorf("Get() want error for invalid endpoint")
+	}
+}
+
+func TestWithEjection(t *testing.T) {
+	var countA, countB int32
+	serverA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&countA, 1)
+		switch {
+		case r.URL.Query().Has("slow"):
+			<-r.Context().Done()
+		case r.URL.Query().Has("fail"):
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer serverA.Close()
+	serverB := startCountServer(http.StatusOK, &countB)
+	defer serverB.Close()
+
+	tests := []struct {
+		name  string
+		query string
+		// wantA is the requests to A of the first and the next two requests
+		wantA int32
+	}{
+		{name: "canceled by the caller", query: "slow", wantA: 2},
+		{name: "server error", query: "fail", wantA: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&countA, 0)
+			client := NewClient(WithEndpoints([]string{serverA.URL, serverB.URL}, RoundRobin),
+				WithEjection(1, time.Minute))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if res, err := client.Get("/?"+tt.query, WithContext(ctx)); err == nil {
+				res.Close()
+			}
+			for i := 0; i < 2; i++ {
+				res, err := client.Get("/")
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				res.Close()
+			}
+			if got := atomic.LoadInt32(&countA); got != tt.wantA {
+				t.Errorf("Get() requests to A got = %d, want %d", got, tt.wantA)
+			}
+		})
+	}
+}
+
+func TestWithEndpoints_NotSent(t *testing.T) {
+	var count1, count2 int32
+	server1 := startCountServer(http.StatusOK, &count1)
+	defer server1.Close()
+	server2 := startCountServer(http.StatusOK, &count2)
+	defer server2.Close()
+
+	client := NewClient(WithEndpoints([]string{server1.URL, server2.URL}, LeastOutstanding),
+		WithDefaultSigner(SignerFunc(func(req *http.Request, body []byte) error {
+			return errors.New("no credentials")
+		})))
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get("/"); err == nil {
+			t.Fatalf("Get() want error of the signer")
+		}
+	}
+	for i, ep := range client.balancer.endpoints {
+		if ep.outstanding != 0 || ep.failures != 0 {
+			t.Errorf("endpoint %d outstanding = %d, failures = %d, want 0", i, ep.outstanding, ep.failures)
+		}
+	}
+	if count1+count2 != 0 {
+		t.Errorf("Get() requests got = %d, want 0", count1+count2)
+	}
+}
@@ -0,0 +1,255 @@
+package httpx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// taggedField is a struct field with the given tag
+type taggedField struct {
+	name  string
+	opts  []string
+	field reflect.StructField
+	value reflect.Value
+}
+
+func (f taggedField) hasOpt(opt string) bool {
+	for _, o := range f.opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// skip reports whether the field is omitted, nil pointers are always omitted
+func (f taggedField) skip() bool {
+	if (f.value.Kind() == reflect.Pointer || f.value.Kind() == reflect.Interface) && f.value.IsNil() {
+		return true
+	}
+	return f.hasOpt("omitempty") && f.value.IsZero()
+}
+
+// taggedFields returns the fields of a struct having the tag, embedded structs are flattened
+// the tag value is "name[,option...]", empty name is the field name
+func taggedFields(obj any, tag string) ([]taggedField, error) {
+	val := reflect.ValueOf(obj)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil, nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T is not a struct", obj)
+	}
+	return appendTaggedFields(nil, val, tag), nil
+}
+
+func appendTaggedFields(fields []taggedField, val reflect.Value, tag string) []taggedField {
+	valType := val.Type()
+	for i := 0; i < valType.NumField(); i++ {
+		field := valType.Field(i)
+		tagValue, ok := field.Tag.Lookup(tag)
+		if !ok {
+			if field.Anonymous {
+				embedded := val.Field(i)
+				if embedded.Kind() == reflect.Pointer {
+					if embedded.IsNil() {
+						continue
+					}
+					embedded = embedded.Elem()
+				}
+				if embedded.Kind() == reflect.Struct {
+					fields = appendTaggedFields(fields, embedded, tag)
+				}
+			}
+			continue
+		}
+		if !field.IsExported() || tagValue == "-" {
+			continue
+		}
+
+		opts := strings.Split(tagValue, ",")
+		name := opts[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		fields = append(fields, taggedField{
+			name:  name,
+			opts:  opts[1:],
+			field: field,
+			value: val.Field(i),
+		})
+	}
+	return fields
+}
+
+// formatValue formats a scalar value
+// time.Time is formatted with layout, RFC3339 if empty, or unix, unixmilli options
+func (f taggedField) formatValue(val reflect.Value) string {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return ""
+		}
+		val = val.Elem()
+	}
+	if val.CanInterface() {
+		switch v := val.Interface().(type) {
+		case time.Time:
+			switch {
+			case f.hasOpt("unix"):
+				return strconv.FormatInt(v.Unix(), 10)
+			case f.hasOpt("unixmilli"):
+				return strconv.FormatInt(v.UnixMilli(), 10)
+			}
+			if layout := f.field.Tag.Get("layout"); len(layout) != 0 {
+				return v.Format(layout)
+			}
+			return v.Format(time.RFC3339)
+		case fmt.Stringer:
+			return v.String()
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		return val.String()
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 64)
+	default:
+		// fmt prints the value held by reflect.Value
+		return fmt.Sprint(val)
+	}
+}
+
+// formatValues formats the field, each element of a slice is a value
+// unless comma or space option joins them
+func (f taggedField) formatValues() []string {
+	val := f.value
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if (val.Kind() != reflect.Slice && val.Kind() != reflect.Array) || val.Type().Elem().Kind() == reflect.Uint8 {
+		return []string{f.formatValue(val)}
+	}
+
+	values := make([]string, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		values = append(values, f.formatValue(val.Index(i)))
+	}
+	switch {
+	case f.hasOpt("comma"):
+		return []string{strings.Join(values, ",")}
+	case f.hasOpt("space"):
+		return []string{strings.Join(values, " ")}
+	}
+	return values
+}
+
+// escapePathSegment escapes a value to be a single path segment
+func escapePathSegment(value string) string {
+	escaped := url.PathEscape(value)
+	// dot segments would be resolved by the server
+	if escaped == "." || escaped == ".." {
+		escaped = strings.ReplaceAll(escaped, ".", "%2E")
+	}
+	return escaped
+}
+
+// expandPath replaces {name} placeholders of the template with escaped params
+func expandPath(template string, params map[string]string) (string, error) {
+	var sb strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			sb.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in %s", template)
+		}
+		end += start
+
+		name := rest[start+1 : end]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("missing path param %s for %s", name, template)
+		}
+		sb.WriteString(rest[:start])
+		sb.WriteString(escapePathSegment(value))
+		rest = rest[end+1:]
+	}
+	return sb.String(), nil
+}
+
+// pathParams converts a map or a struct with path tags to path params
+func pathParams(params any) (map[string]string, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if m, ok := params.(map[string]string); ok {
+		return m, nil
+	}
+
+	result := make(map[string]string)
+	val := reflect.ValueOf(params)
+	if val.Kind() == reflect.Map {
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%T is not a map with string keys", params)
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = taggedField{}.formatValue(iter.Value())
+		}
+		return result, nil
+	}
+
+	fields, err := taggedFields(params, "path")
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		result[field.name] = field.formatValue(field.value)
+	}
+	return result, nil
+}
+
+// structValues encodes the fields of obj with the tag into url values
+// the brackets option appends [] to the name of slice values
+func structValues(obj any, tag string) (url.Values, error) {
+	fields, err := taggedFields(obj, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values)
+	for _, field := range fields {
+		if field.skip() {
+			continue
+		}
+		name := field.name
+		if field.hasOpt("brackets") {
+			name += "[]"
+		}
+		values[name] = append(values[name], field.formatValues()...)
+	}
+	return values, nil
+}
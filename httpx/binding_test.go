@@ -0,0 +1,138 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// startURLServer responds the escaped path and raw query of the request
+func startURLServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(r.URL.EscapedPath() + "?" + r.URL.RawQuery))
+	}))
+}
+
+func TestWithPathParams(t *testing.T) {
+	server := startURLServer()
+	defer server.Close()
+
+	type postParams struct {
+		Id     int    `path:"id"`
+		PostId string `path:"postId"`
+	}
+	tests := []struct {
+		name    string
+		params  any
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "map[string]string",
+			params: map[string]string{"id": "1", "postId": "a/b c"},
+			want:   "/users/1/posts/a%2Fb%20c?",
+		},
+		{
+			name:   "map[string]any",
+			params: map[string]any{"id": 1, "postId": 2.5},
+			want:   "/users/1/posts/2.5?",
+		},
+		{
+			name:   "struct",
+			params: postParams{Id: 1, PostId: "?#"},
+			want:   "/users/1/posts/%3F%23?",
+		},
+		{
+			name:   "dot segment",
+			params: &postParams{Id: 1, PostId: ".."},
+			want:   "/users/1/posts/%2E%2E?",
+		},
+		{
+			name:    "missing param",
+			params:  map[string]string{"id": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "not a struct",
+			params:  "1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewClient().Get(server.URL,
+				WithPathParams("/users/{id}/posts/{postId}", tt.params),
+				WithBodyParser("text/plain", TextBodyParser))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got string
+			if err = res.Unmarshal(&got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("WithPathParams() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithQueryStruct(t *testing.T) {
+	server := startURLServer()
+	defer server.Close()
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	limit := 0
+
+	type embedded struct {
+		Page int `url:"page"`
+	}
+	type query struct {
+		embedded
+		Name     string    `url:"name"`
+		Empty    string    `url:"empty,omitempty"`
+		Tags     []string  `url:"tag"`
+		Ids      []int     `url:"ids,comma"`
+		Kinds    []string  `url:"kind,brackets"`
+		Since    time.Time `url:"since"`
+		Day      time.Time `url:"day" layout:"2006-01-02"`
+		Unix     time.Time `url:"unix,unix"`
+		Limit    *int      `url:"limit"`
+		Offset   *int      `url:"offset"`
+		Ignored  string    `url:"-"`
+		Untagged string
+	}
+	res, err := NewClient().Get(server.URL,
+		WithQueryStruct(query{
+			embedded: embedded{Page: 2},
+			Name:     "a b",
+			Tags:     []string{"x", "y"},
+			Ids:      []int{1, 2, 3},
+			Kinds:    []string{"k"},
+			Since:    since,
+			Day:      since,
+			Unix:     since,
+			Limit:    &limit,
+			Ignored:  "ignored",
+			Untagged: "untagged",
+		}),
+		WithBodyParser("text/plain", TextBodyParser))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var got string
+	if err = res.Unmarshal(&got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := "/?day=2024-01-02&ids=1%2C2%2C3&kind%5B%5D=k&limit=0&name=a+b&page=2" +
+		"&since=2024-01-02T03%3A04%3A05Z&tag=x&tag=y&unix=1704164645"
+	if got != want {
+		t.Errorf("WithQueryStruct() got  = %v\nwant %v", got, want)
+	}
+}
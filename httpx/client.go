@@ -2,19 +2,35 @@ package httpx
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
 	neturl "net/url"
+	"time"
 )
 
 type Client struct {
 	client             http.Client
 	defaultHeaders     map[string][]string
+	headerMergePolicy  HeaderMergePolicy
 	bodyParsers        map[string]BodyParser
 	disableCompression bool
+	baseURL            *url.URL
+	baseURLErr         error
+	pathPrefix         string
+	defaultQueries     map[string][]string
+	balancer           *balancer
+	retries            int
+	metrics            MetricsCollector
+	tracer             Tracer
+	maxResponseSize    int64
+	contentEncoders    map[string]ContentEncoder
+	contentDecoders    map[string]ContentDecoder
+	acceptEncoding     bool
+	signer             Signer
 }
 
 type Marshaller func(objPtr any) ([]byte, error)
@@ -36,6 +52,7 @@ func NewClient(options ...ClientOption) *Client {
 			//KeepAlive: 15 * time.Second,
 		}).DialContext,
 		TLSHandshakeTimeout: co.timeout,
+		TLSClientConfig:     co.tlsConfig,
 		DisableCompression:  co.disableCompression,
 	}
 
@@ -48,10 +65,58 @@ func NewClient(options ...ClientOption) *Client {
 		bodyParsers:        co.bodyParsers,
 		disableCompression: co.disableCompression,
 		defaultHeaders:     co.headers,
+		headerMergePolicy:  co.headerMergePolicy,
+		pathPrefix:         co.pathPrefix,
+		defaultQueries:     co.queries,
+		retries:            co.retries,
+		metrics:            co.metrics,
+		tracer:             co.tracer,
+		maxResponseSize:    co.maxResponseSize,
+		contentEncoders:    co.contentEncoders,
+		contentDecoders:    co.contentDecoders,
+		acceptEncoding:     co.acceptEncoding,
+		signer:             co.signer,
+	}
+	if len(co.baseURL) != 0 {
+		client.baseURL, client.baseURLErr = neturl.Parse(co.baseURL)
+	}
+	if len(co.endpoints) != 0 && client.baseURLErr == nil {
+		client.balancer, client.baseURLErr = newBalancer(co.endpoints, co.balancePolicy, co.ejectFailures, co.ejectCooldown)
 	}
 	return &client
 }
 
+// resolveURL resolves a relative url against the base url and inserts the path prefix
+// the path prefix is not inserted to absolute urls
+func (c *Client) resolveURL(base *url.URL, u *url.URL) (*url.URL, error) {
+	if c.baseURLErr != nil {
+		return nil, fmt.Errorf("invalid base url: %s", c.baseURLErr)
+	}
+
+	resolved := *u
+	if base != nil && !u.IsAbs() && len(u.Host) == 0 {
+		resolved = *base
+		resolved.RawQuery = mergeRawQuery(base.RawQuery, u.RawQuery)
+		if len(u.Fragment) != 0 {
+			resolved.Fragment = u.Fragment
+		}
+		return resolved.JoinPath(c.pathPrefix, u.EscapedPath()), nil
+	}
+	// an absolute url like a Link header or a presigned url is sent as it is
+	return &resolved, nil
+}
+
+func mergeRawQuery(base, query string) string {
+	switch {
+	case len(base) == 0:
+		return query
+	case len(query) == 0:
+		return base
+	default:
+		return base + "&" + query
+	}
+}
+
 // Do makes a request to the url with given options
 // res should be Closed after use
 func (c *Client) Do(method string, url *url.URL, options ...ReqOption) (res *Response, err error) {
@@ -66,6 +131,96 @@ func (c *Client) Do(method string, url *url.URL, options ...ReqOption) (res *Res
 		}
 	}
 
+	// compression
+	if err = c.encodeBody(req); err != nil {
+		return
+	}
+
+	// body is kept to be sent again on retry
+	var body []byte
+	if req.body != nil {
+		body = req.body.Bytes()
+	}
+
+	// the key may be removed by a later WithoutHeader
+	retries := 0
+	if isIdempotent(method) || req.idempotent && len(req.headers[IdempotencyKeyHeader]) != 0 {
+		retries = c.retries
+	}
+	var tried map[*endpoint]bool
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		// base url
+		base := c.baseURL
+		var ep *endpoint
+		if c.balancer != nil && !url.IsAbs() && len(url.Host) == 0 {
+			ep = c.balancer.pick(tried)
+			base = ep.url
+		}
+
+		var hreq *http.Request
+		if hreq, err = c.newHTTPRequest(method, base, url, req, body); err != nil {
+			if ep != nil {
+				c.balancer.done(ep, attemptNotSent)
+			}
+			return
+		}
+
+		// make request
+		hreq, span := c.startSpan(hreq)
+		start := time.Now()
+		resp, err = c.client.Do(hreq)
+		endSpan(span, resp, err)
+		if c.metrics != nil {
+			c.observe(hreq, req, start, resp, err)
+		}
+		if ep != nil {
+			c.balancer.done(ep, resultOf(req.ctx, resp, err))
+			if tried == nil {
+				tried = make(map[*endpoint]bool)
+			}
+			tried[ep] = true
+		}
+		if attempt >= retries || req.ctx.Err() != nil || !shouldRetry(resp, err) {
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to request: %s", err)
+		return
+	}
+
+	// content encoding
+	if err = c.decodeBody(resp); err != nil {
+		return
+	}
+
+	// max response size
+	limit := c.maxResponseSize
+	if req.maxResponseSize != 0 {
+		limit = req.maxResponseSize
+	}
+	if limit > 0 && resp.ContentLength > limit {
+		resp.Body.Close()
+		err = &ResponseTooLargeError{Limit: limit, ContentLength: resp.ContentLength}
+		return
+	}
+
+	res = c.newResponse(resp, req, limit)
+	return
+}
+
+// newHTTPRequest builds the request to the url resolved against base
+func (c *Client) newHTTPRequest(method string, base *url.URL, url *url.URL, req *Request, body []byte) (hreq *http.Request, err error) {
+
+	// base url and path prefix
+	if url, err = c.resolveURL(base, url); err != nil {
+		return
+	}
+
 	// path
 	if len(req.path) != 0 {
 		url = url.JoinPath(req.path)
@@ -73,6 +228,16 @@ func (c *Client) Do(method string, url *url.URL, options ...ReqOption) (res *Res
 
 	// query
 	queries := url.Query()
+	for q, values := range c.defaultQueries {
+		// the request overrides the default
+		if _, ok := queries[q]; ok {
+			continue
+		}
+		if _, ok := req.queries[q]; ok {
+			continue
+		}
+		queries[q] = append([]string{}, values...)
+	}
 	for q, values := range req.queries {
 		for _, v := range values {
 			queries.Add(q, v)
@@ -81,46 +246,70 @@ func (c *Client) Do(method string, url *url.URL, options ...ReqOption) (res *Res
 	url.RawQuery = queries.Encode()
 
 	// body
-	var body io.Reader = req.body
-	if req.body == nil {
-		body = nil
+	var bodyReader io.Reader
+	if req.body != nil {
+		bodyReader = bytes.NewReader(body)
+		if req.uploadProgress != nil || req.uploadLimit > 0 {
+			bodyReader = newProgressReader(req.ctx, bodyReader, int64(len(body)), req.uploadProgress, req.uploadLimit)
+		}
 	}
 
-	hreq, err := http.NewRequest(method, url.String(), body)
+	hreq, err = http.NewRequestWithContext(req.ctx, method, url.String(), bodyReader)
 	if err != nil {
 		err = fmt.Errorf("failed to create request: %s", err)
 		return
 	}
+	if _, ok := bodyReader.(*progressReader); ok {
+		hreq.ContentLength = int64(len(body))
+	}
 
 	// headers
-	// add default headers
+	// default headers, keys are canonical
 	for k, values := range c.defaultHeaders {
-		for _, v := range values {
-			hreq.Header.Add(k, v)
+		if _, removed := req.removedHeaders[k]; removed {
+			continue
 		}
+		hreq.Header[k] = append([]string{}, values...)
 	}
 	// request headers
 	for k, values := range req.headers {
-		for _, v := range values {
-			hreq.Header.Add(k, v)
+		if c.headerMergePolicy == HeaderMergeAdd {
+			hreq.Header[k] = append(hreq.Header[k], values...)
+		} else {
+			hreq.Header[k] = append([]string{}, values...)
 		}
 	}
+	// empty User-Agent prevents the default of the transport
+	if _, removed := req.removedHeaders["User-Agent"]; removed && len(hreq.Header["User-Agent"]) == 0 {
+		hreq.Header["User-Agent"] = []string{""}
+	}
 	if len(req.contentType) != 0 {
 		hreq.Header.Set("Content-Type", req.contentType)
 	}
+	// with Accept-Encoding the transport leaves the body to the decoders
+	if _, removed := req.removedHeaders["Accept-Encoding"]; c.acceptEncoding && !removed && len(hreq.Header["Accept-Encoding"]) == 0 {
+		hreq.Header.Set("Accept-Encoding", c.acceptEncodings())
+	}
 
-	// make request
-	resp, err := c.client.Do(hreq)
-	if err != nil {
-		err = fmt.Errorf("failed to request: %s", err)
-		return
+	// signing is the last
+	signer := c.signer
+	if req.signerSet {
+		signer = req.signer
 	}
+	if signer != nil {
+		if err = signer.Sign(hreq, body); err != nil {
+			err = fmt.Errorf("failed to sign: %s", err)
+			return
+		}
+	}
+	return
+}
 
-	// response
-	res = &Response{
+func (c *Client) newResponse(resp *http.Response, req *Request, limit int64) *Response {
+	res := &Response{
 		res:         resp,
 		bufBody:     nil,
-		bodyParsers: c.bodyParsers,
+		bodyParsers: make(map[string]BodyParser),
 	}
 
 	// bodyparser
@@ -139,9 +328,19 @@ func (c *Client) Do(method string, url *url.URL, options ...ReqOption) (res *Res
 			resp.Body = nil
 		}
 	} else {
+		if limit > 0 {
+			res.limit = &limitReadCloser{rc: resp.Body, limit: limit}
+			resp.Body = res.limit
+		}
+		if req.responseProgress != nil || req.responseLimit > 0 {
+			resp.Body = &progressReadCloser{
+				progressReader: newProgressReader(req.ctx, resp.Body, resp.ContentLength, req.responseProgress, req.responseLimit),
+				closer:         resp.Body,
+			}
+		}
 		res.bufBody = bufio.NewReaderSize(resp.Body, 4*1024)
 	}
-	return
+	return res
 }
 
 func (c *Client) Get(url string, options ...ReqOption) (res *Response, err error) {
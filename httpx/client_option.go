@@ -1,6 +1,8 @@
 package httpx
 
 import (
+	"crypto/tls"
+	"net/http"
 	"time"
 )
 
@@ -14,15 +16,47 @@ type clientOptions struct {
 	// the Transport requests gzip on its own and gets a gzipped response
 	disableCompression bool // false
 	headers            map[string][]string
+	headerMergePolicy  HeaderMergePolicy
+	baseURL            string
+	pathPrefix         string
+	queries            map[string][]string
+	endpoints          []string
+	balancePolicy      BalancePolicy
+	ejectFailures      int
+	ejectCooldown      time.Duration
+	retries            int
+	metrics            MetricsCollector
+	tracer             Tracer
+	maxResponseSize    int64
+	contentEncoders    map[string]ContentEncoder
+	contentDecoders    map[string]ContentDecoder
+	acceptEncoding     bool
+	tlsConfig          *tls.Config
+	signer             Signer
 }
 
 type ClientOption func(clientOptions *clientOptions)
 
+// HeaderMergePolicy decides how a request header is merged with the default header of the same key
+type HeaderMergePolicy int
+
+const (
+	// HeaderMergeSet replaces the default values with the request values(default)
+	HeaderMergeSet HeaderMergePolicy = iota
+	// HeaderMergeAdd adds the request values to the default values
+	HeaderMergeAdd
+)
+
 func defaultClientOptions() clientOptions {
 	co := clientOptions{
 		timeout:            DefaultTimeout,
 		bodyParsers:        make(map[string]BodyParser),
 		disableCompression: false,
+		ejectFailures:      DefaultEjectFailures,
+		ejectCooldown:      DefaultEjectCooldown,
+		tracer:             NoopTracer,
+		contentEncoders:    defaultContentEncoders(),
+		contentDecoders:    defaultContentDecoders(),
 	}
 	co.bodyParsers["application/json"] = JsonBodyParser
 	return co
@@ -34,14 +68,107 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithTLSConfig sets the TLS configuration of the transport
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.tlsConfig = config
+	}
+}
+
+// WithDisableCompression stops the transport requesting gzip and decoding the response
+// Content-Encoding sent anyway is decoded by the content decoders
+func WithDisableCompression(disable bool) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.disableCompression = disable
+	}
+}
+
+// WithDefaultHeaders sets the default headers sent with every request
+// it replaces the defaults of the same keys and keeps the others
 func WithDefaultHeaders(headers map[string][]string) ClientOption {
 	return func(clientOptions *clientOptions) {
-		clientOptions.headers = headers
+		for key, values := range headers {
+			clientOptions.setHeader(key, values...)
+		}
 	}
 }
 
+// WithDefaultHeader sets a default header sent with every request
+func WithDefaultHeader(key string, values ...string) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.setHeader(key, values...)
+	}
+}
+
+// WithUserAgent sets the default User-Agent header
+func WithUserAgent(userAgent string) ClientOption {
+	return WithDefaultHeader("User-Agent", userAgent)
+}
+
+// WithHeaderMergePolicy sets how request headers are merged with the default headers
+func WithHeaderMergePolicy(policy HeaderMergePolicy) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.headerMergePolicy = policy
+	}
+}
+
+func (co *clientOptions) setHeader(key string, values ...string) {
+	if co.headers == nil {
+		co.headers = make(map[string][]string)
+	}
+	co.headers[http.CanonicalHeaderKey(key)] = append([]string{}, values...)
+}
+
 func WithDefaultBodyParser(contentType string, parser BodyParser) ClientOption {
 	return func(clientOptions *clientOptions) {
 		clientOptions.bodyParsers[contentType] = parser
 	}
 }
+
+// WithDefaultBodyParsers adds all the parsers keyed by content type
+func WithDefaultBodyParsers(parsers map[string]BodyParser) ClientOption {
+	return func(clientOptions *clientOptions) {
+		for contentType, parser := range parsers {
+			clientOptions.bodyParsers[contentType] = parser
+		}
+	}
+}
+
+// WithBaseURL sets the url relative urls of requests are resolved against
+func WithBaseURL(baseURL string) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.baseURL = baseURL
+	}
+}
+
+// WithPathPrefix sets the path prefix inserted before the path of relative urls
+// resolved against the base url or the endpoints, absolute urls are sent as they are
+func WithPathPrefix(prefix string) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.pathPrefix = prefix
+	}
+}
+
+// WithDefaultQuery adds a query param to every request
+// it is not added if the request has a query param with the same key
+func WithDefaultQuery(key, value string) ClientOption {
+	return func(clientOptions *clientOptions) {
+		if clientOptions.queries == nil {
+			clientOptions.queries = make(map[string][]string)
+		}
+		clientOptions.queries[key] = append(clientOptions.queries[key], value)
+	}
+}
+
+// WithDefaultQueries adds query params to every request
+// it is not added if the request has a query param with the same key
+func WithDefaultQueries(queries map[string][]string) ClientOption {
+	return func(clientOptions *clientOptions) {
+		if clientOptions.queries == nil {
+			clientOptions.queries = make(map[string][]string)
+		}
+		for key, values := range queries {
+			clientOptions.queries[key] = append(clientOptions.queries[key], values...)
+		}
+	}
+}
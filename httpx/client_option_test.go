@@ -0,0 +1,168 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWithBaseURL(t *testing.T) {
+	server := startURLServer()
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		client  *Client
+		url     string
+		options []ReqOption
+		want    string
+	}{
+		{
+			name:   "relative url",
+			client: NewClient(WithBaseURL(server.URL + "/api")),
+			url:    "users/1",
+			want:   "/api/users/1?",
+		},
+		{
+			name:    "empty url with path",
+			client:  NewClient(WithBaseURL(server.URL)),
+			url:     "",
+			options: []ReqOption{WithPath("/users"), WithQuery("page", "1")},
+			want:    "/users?page=1",
+		},
+		{
+			name:   "base url query",
+			client: NewClient(WithBaseURL(server.URL + "/api?key=secret")),
+			url:    "/users?page=2",
+			want:   "/api/users?key=secret&page=2",
+		},
+		{
+			name:   "path prefix",
+			client: NewClient(WithBaseURL(server.URL), WithPathPrefix("/api/v2")),
+			url:    "/users",
+			want:   "/api/v2/users?",
+		},
+		{
+			name:   "path prefix - absolute url as it is",
+			client: NewClient(WithBaseURL(server.URL), WithPathPrefix("/api/v2")),
+			url:    server.URL + "/api/v2/users",
+			want:   "/api/v2/users?",
+		},
+		{
+			name:   "absolute url ignores base url",
+			client: NewClient(WithBaseURL("http://invalid.invalid")),
+			url:    server.URL + "/users",
+			want:   "/users?",
+		},
+		{
+			name:   "default query",
+			client: NewClient(WithBaseURL(server.URL), WithDefaultQuery("version", "1"), WithDefaultQuery("lang", "en")),
+			url:    "/users",
+			want:   "/users?lang=en&version=1",
+		},
+		{
+			name:    "default query - overridden by request",
+			client:  NewClient(WithBaseURL(server.URL), WithDefaultQueries(map[string][]string{"version": {"1"}})),
+			url:     "/users",
+			options: []ReqOption{WithQuery("version", "2")},
+			want:    "/users?version=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append(tt.options, WithBodyParser("text/plain", TextBodyParser))
+			res, err := tt.client.Get(tt.url, options...)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			var got string
+			if err = res.Unmarshal(&got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBaseURL_Invalid(t *testing.T) {
+	client := NewClient(WithBaseURL("http://[::1"))
+	if _, err := client.Get("/users"); err == nil {
+		t.Errorf("Get() want error for invalid base url")
+	}
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+	}))
+	defer server.Close()
+
+	defaults := []ClientOption{
+		WithDefaultHeaders(map[string][]string{"x-api-key": {"key"}, "Accept": {"application/json"}}),
+		WithDefaultHeaders(map[string][]string{"X-Tenant": {"tenant"}}),
+		WithUserAgent("httpx-test/1.0"),
+	}
+	tests := []struct {
+		name    string
+		client  *Client
+		options []ReqOption
+		want    map[string][]string
+	}{
+		{
+			name:   "defaults are merged",
+			client: NewClient(defaults...),
+			want: map[string][]string{
+				"X-Api-Key":  {"key"},
+				"Accept":     {"application/json"},
+				"X-Tenant":   {"tenant"},
+				"User-Agent": {"httpx-test/1.0"},
+			},
+		},
+		{
+			name:    "request overrides default",
+			client:  NewClient(defaults...),
+			options: []ReqOption{WithHeader("accept", "text/plain"), WithHeaders(map[string]string{"user-agent": "other"})},
+			want: map[string][]string{
+				"Accept":     {"text/plain"},
+				"User-Agent": {"other"},
+			},
+		},
+		{
+			name:    "request adds to default",
+			client:  NewClient(append(defaults, WithHeaderMergePolicy(HeaderMergeAdd))...),
+			options: []ReqOption{WithHeader("accept", "text/plain")},
+			want: map[string][]string{
+				"Accept": {"application/json", "text/plain"},
+			},
+		},
+		{
+			name:    "without header",
+			client:  NewClient(defaults...),
+			options: []ReqOption{WithHeader("X-Debug", "1"), WithoutHeader("x-api-key"), WithoutHeader("x-debug"), WithoutHeader("User-Agent")},
+			want: map[string][]string{
+				"X-Api-Key":  nil,
+				"X-Debug":    nil,
+				"User-Agent": nil,
+				"X-Tenant":   {"tenant"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.client.Get(server.URL, tt.options...)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			res.Close()
+			for key, want := range tt.want {
+				if got := gotHeader[key]; !reflect.DeepEqual(got, want) {
+					t.Errorf("Get() header %s got = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
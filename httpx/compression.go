@@ -0,0 +1,175 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// ContentEncoder compresses a request body written to w
+type ContentEncoder func(w io.Writer) (io.WriteCloser, error)
+
+// ContentDecoder decompresses a response body read from r
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+func defaultContentEncoders() map[string]ContentEncoder {
+	return map[string]ContentEncoder{
+		EncodingGzip: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		// deflate of http is the zlib format
+		EncodingDeflate: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+	}
+}
+
+func defaultContentDecoders() map[string]ContentDecoder {
+	return map[string]ContentDecoder{
+		EncodingGzip: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		EncodingDeflate: deflateDecoder,
+	}
+}
+
+// deflateDecoder decodes the zlib format, or raw deflate sent by some servers
+func deflateDecoder(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// WithContentEncoder adds the encoder of the encoding for WithCompression, like zstd
+func WithContentEncoder(encoding string, encoder ContentEncoder) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.contentEncoders[strings.ToLower(encoding)] = encoder
+	}
+}
+
+// WithContentDecoder adds the decoder of the response Content-Encoding, like br or zstd
+// with any decoder added, Accept-Encoding of all the decoders is sent unless the request has one
+func WithContentDecoder(encoding string, decoder ContentDecoder) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.contentDecoders[strings.ToLower(encoding)] = decoder
+		clientOptions.acceptEncoding = true
+	}
+}
+
+// WithCompression compresses the body with the encoding and sets Content-Encoding
+// gzip and deflate are supported, others are added by WithContentEncoder
+func WithCompression(encoding string) ReqOption {
+	return func(req *Request) error {
+		req.contentEncoding = strings.ToLower(encoding)
+		return nil
+	}
+}
+
+// encodeBody compresses the body with the content encoding of the request
+func (c *Client) encodeBody(req *Request) error {
+	if len(req.contentEncoding) == 0 || req.body == nil {
+		return nil
+	}
+	encoder, ok := c.contentEncoders[req.contentEncoding]
+	if !ok {
+		return fmt.Errorf("no encoder found for %s", req.contentEncoding)
+	}
+
+	var buf bytes.Buffer
+	w, err := encoder(&buf)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %s", req.contentEncoding, err)
+	}
+	if _, err = w.Write(req.body.Bytes()); err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %s", req.contentEncoding, err)
+	}
+	req.body = &buf
+	req.headers["Content-Encoding"] = []string{req.contentEncoding}
+	return nil
+}
+
+// acceptEncodings returns Accept-Encoding of all the decoders
+func (c *Client) acceptEncodings() string {
+	encodings := make([]string, 0, len(c.contentDecoders))
+	for encoding := range c.contentDecoders {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return strings.Join(encodings, ", ")
+}
+
+// decodeBody decodes the body of Content-Encoding the transport has not decoded
+// the body is left as it is if any of the encodings has no decoder
+func (c *Client) decodeBody(resp *http.Response) error {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	var encodings []string
+	for _, value := range resp.Header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if len(encoding) != 0 && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+	if len(encodings) == 0 {
+		return nil
+	}
+	for _, encoding := range encodings {
+		if _, ok := c.contentDecoders[encoding]; !ok {
+			return nil
+		}
+	}
+
+	// the last applied encoding is decoded first
+	body := &decodedBody{Reader: resp.Body, closers: []io.Closer{resp.Body}}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, err := c.contentDecoders[encodings[i]](body.Reader)
+		if err != nil {
+			body.Close()
+			return fmt.Errorf("failed to decode %s: %s", encodings[i], err)
+		}
+		body.Reader = decoder
+		body.closers = append(body.closers, decoder)
+	}
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody closes the decoders and the body
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decodedBody) Close() (err error) {
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if closeErr := b.closers[i].Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
@@ -0,0 +1,173 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithCompression(t *testing.T) {
+	var gotEncoding, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get("Content-Encoding")
+		decoders := defaultContentDecoders()
+		decoders["reverse"] = reverseDecoder
+		var body io.Reader = r.Body
+		if decoder, ok := decoders[gotEncoding]; ok {
+			rc, err := decoder(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = rc
+		}
+		data, _ := io.ReadAll(body)
+		gotBody = string(data)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithContentEncoder("reverse", reverseEncoder))
+	tests := []struct {
+		name     string
+		encoding string
+		wantErr  bool
+	}{
+		{name: "gzip", encoding: "gzip"},
+		{name: "deflate", encoding: "DEFLATE"},
+		{name: "custom encoder", encoding: "reverse"},
+		{name: "unknown encoder", encoding: "zstd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotEncoding, gotBody = "", ""
+			res, err := client.Post(server.URL, WithCompression(tt.encoding), WithString("text/plain", "hello hello hello"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Post() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			res.Close()
+			if gotEncoding != strings.ToLower(tt.encoding) || gotBody != "hello hello hello" {
+				t.Errorf("Post() got = %s %q, want %s %q", gotEncoding, gotBody, tt.encoding, "hello hello hello")
+			}
+		})
+	}
+}
+
+func TestContentDecoder(t *testing.T) {
+	var gotAcceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAcceptEncoding = r.Header.Get("Accept-Encoding")
+		encoding := r.URL.Query().Get("encoding")
+		var buf bytes.Buffer
+		var enc io.WriteCloser
+		switch encoding {
+		case "gzip":
+			enc = gzip.NewWriter(&buf)
+		case "deflate":
+			enc = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			enc, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+			encoding = "deflate"
+		case "reverse":
+			enc, _ = reverseEncoder(&buf)
+		default:
+			enc = nopWriteCloser{&buf}
+		}
+		enc.Write([]byte("decoded"))
+		enc.Close()
+		w.Header().Set("Content-Type", "text/plain")
+		if len(encoding) != 0 {
+			w.Header().Set("Content-Encoding", encoding)
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name               string
+		client             *Client
+		encoding           string
+		want               string
+		wantAcceptEncoding string
+	}{
+		{name: "deflate", client: NewClient(), encoding: "deflate", want: "decoded", wantAcceptEncoding: "gzip"},
+		{name: "raw deflate", client: NewClient(), encoding: "raw-deflate", want: "decoded"},
+		{name: "gzip without transport", client: NewClient(WithDisableCompression(true)), encoding: "gzip", want: "decoded"},
+		{
+			name:               "custom decoder",
+			client:             NewClient(WithContentDecoder("reverse", reverseDecoder)),
+			encoding:           "reverse",
+			want:               "decoded",
+			wantAcceptEncoding: "deflate, gzip, reverse",
+		},
+		{name: "unknown encoding is kept", client: NewClient(), encoding: "reverse", want: "dedoced"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.client.Get(server.URL+"?encoding="+tt.encoding, WithBodyParser("text/plain", TextBodyParser))
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			var got string
+			if err = res.Unmarshal(&got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal() got = %v, want %v", got, tt.want)
+			}
+			if len(tt.wantAcceptEncoding) != 0 && gotAcceptEncoding != tt.wantAcceptEncoding {
+				t.Errorf("Accept-Encoding got = %v, want %v", gotAcceptEncoding, tt.wantAcceptEncoding)
+			}
+		})
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// reverseEncoder reverses the bytes for a custom encoding
+func reverseEncoder(w io.Writer) (io.WriteCloser, error) {
+	return &reverseWriter{w: w}, nil
+}
+
+type reverseWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (r *reverseWriter) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	return len(p), nil
+}
+
+func (r *reverseWriter) Close() error {
+	_, err := r.w.Write(reverse(r.buf))
+	return err
+}
+
+func reverseDecoder(r io.Reader) (io.ReadCloser, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(reverse(data))), nil
+}
+
+func reverse(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	return reversed
+}
@@ -0,0 +1,306 @@
+package httpx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DownloadTempSuffix is appended to the destination for the partial file of a resumable download
+const DownloadTempSuffix = ".download"
+
+// DownloadValidatorSuffix is appended to the partial file for the ETag or Last-Modified of the file
+// it is sent in If-Range on resume, so a changed file is downloaded again
+const DownloadValidatorSuffix = ".validator"
+
+type downloadOptions struct {
+	progress   ProgressFunc
+	checksum   string
+	resume     bool
+	reqOptions []ReqOption
+}
+
+type DownloadOption func(downloadOptions *downloadOptions)
+
+func defaultDownloadOptions() downloadOptions {
+	return downloadOptions{
+		resume: true,
+	}
+}
+
+// WithDownloadProgress sets the callback reporting the progress of the download
+func WithDownloadProgress(progress ProgressFunc) DownloadOption {
+	return func(downloadOptions *downloadOptions) {
+		downloadOptions.progress = progress
+	}
+}
+
+// WithDownloadChecksum verifies the downloaded file with hex encoded SHA-256 checksum
+func WithDownloadChecksum(sha256Hex string) DownloadOption {
+	return func(downloadOptions *downloadOptions) {
+		downloadOptions.checksum = strings.ToLower(sha256Hex)
+	}
+}
+
+// WithDownloadResume enables resuming an interrupted download from the partial file(default true)
+// the partial file is kept in dst + DownloadTempSuffix when a download fails
+// it is resumed only if the server sent ETag or Last-Modified, or with WithDownloadChecksum
+func WithDownloadResume(resume bool) DownloadOption {
+	return func(downloadOptions *downloadOptions) {
+		downloadOptions.resume = resume
+	}
+}
+
+// WithDownloadReqOptions adds options to the download request
+func WithDownloadReqOptions(options ...ReqOption) DownloadOption {
+	return func(downloadOptions *downloadOptions) {
+		downloadOptions.reqOptions = append(downloadOptions.reqOptions, options...)
+	}
+}
+
+// ChecksumError is returned when the downloaded file does not match the checksum
+type ChecksumError struct {
+	Want string
+	Got  string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch: want %s, got %s", e.Want, e.Got)
+}
+
+// Download downloads url to dst
+// the body is written to a temporary file which is renamed to dst when completed
+// and resumed with Range request if the server supports it
+func (c *Client) Download(ctx context.Context, url string, dst string, options ...DownloadOption) (err error) {
+	do := defaultDownloadOptions()
+	for _, opt := range options {
+		opt(&do)
+	}
+
+	var tmpName string
+	var validatorName string
+	if do.resume {
+		tmpName = dst + DownloadTempSuffix
+		validatorName = tmpName + DownloadValidatorSuffix
+	} else {
+		tmpFile, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*"+DownloadTempSuffix)
+		if err != nil {
+			return fmt.Errorf("failed to create temp file: %s", err)
+		}
+		tmpName = tmpFile.Name()
+		tmpFile.Close()
+	}
+	defer func() {
+		// keep the partial file to resume later
+		if err != nil && (!do.resume || errors.As(err, new(*ChecksumError))) {
+			os.Remove(tmpName)
+			if len(validatorName) != 0 {
+				os.Remove(validatorName)
+			}
+		}
+	}()
+
+	var offset int64
+	var validator string
+	if do.resume {
+		if info, statErr := os.Stat(tmpName); statErr == nil {
+			offset = info.Size()
+		}
+		if data, readErr := os.ReadFile(validatorName); readErr == nil {
+			validator = string(data)
+		}
+		// the partial file can be of another version of the file, only the checksum can tell
+		if len(validator) == 0 && len(do.checksum) == 0 {
+			offset = 0
+		}
+	}
+
+	if err = c.download(ctx, url, tmpName, validatorName, offset, validator, &do); err != nil {
+		return err
+	}
+
+	if len(do.checksum) != 0 {
+		if err = verifyChecksum(tmpName, do.checksum); err != nil {
+			return err
+		}
+	}
+
+	if err = os.Rename(tmpName, dst); err != nil {
+		return fmt.Errorf("failed to rename: %s", err)
+	}
+	if len(validatorName) != 0 {
+		os.Remove(validatorName)
+	}
+	return nil
+}
+
+// download writes url to tmpName from offset
+// validator is sent in If-Range, validatorName is empty if not resumable
+func (c *Client) download(ctx context.Context, url string, tmpName string, validatorName string, offset int64, validator string, do *downloadOptions) error {
+	// the body is written to the file, not limited unless the options limit it
+	reqOptions := append([]ReqOption{WithMaxResponseSize(-1)}, do.reqOptions...)
+	reqOptions = append(reqOptions, WithContext(ctx))
+	if offset > 0 {
+		reqOptions = append(reqOptions, WithHeader("Range", fmt.Sprintf("bytes=%d-", offset)))
+		if len(validator) != 0 {
+			reqOptions = append(reqOptions, WithHeader("If-Range", validator))
+		}
+	}
+
+	res, err := c.Get(url, reqOptions...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	total := int64(-1)
+	switch res.StatusCode() {
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(res.res.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			if offset == 0 {
+				return fmt.Errorf("unexpected content range: %s", res.res.Header.Get("Content-Range"))
+			}
+			// the range does not continue the partial file, start over
+			res.Close()
+			os.Remove(tmpName)
+			return c.download(ctx, url, tmpName, validatorName, 0, "", do)
+		}
+		total = size
+		if total < 0 && res.res.ContentLength >= 0 {
+			total = offset + res.res.ContentLength
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file can be already completed
+		if _, size, ok := parseContentRange(res.res.Header.Get("Content-Range")); ok && size == offset {
+			if do.progress != nil {
+				do.progress(offset, size)
+			}
+			return nil
+		}
+		if offset > 0 {
+			res.Close()
+			os.Remove(tmpName)
+			return c.download(ctx, url, tmpName, validatorName, 0, "", do)
+		}
+		return fmt.Errorf("unexpected status: %s", res.Status())
+	default:
+		if err = res.statusError(); err != nil {
+			return err
+		}
+		// the server ignored Range or the file changed, start over
+		offset = 0
+		total = res.res.ContentLength
+	}
+
+	if offset == 0 && len(validatorName) != 0 {
+		if validator = responseValidator(res.res.Header); len(validator) != 0 {
+			if err = os.WriteFile(validatorName, []byte(validator), 0644); err != nil {
+				return fmt.Errorf("failed to write validator: %s", err)
+			}
+		} else {
+			os.Remove(validatorName)
+		}
+	}
+
+	file, err := os.OpenFile(tmpName, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open: %s", err)
+	}
+	defer file.Close()
+
+	if err = file.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate: %s", err)
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek: %s", err)
+	}
+
+	var wr io.Writer = file
+	if do.progress != nil {
+		do.progress(offset, total)
+		wr = &progressWriter{
+			wr:          file,
+			transferred: offset,
+			total:       total,
+			progress:    do.progress,
+		}
+	}
+
+	var body io.Reader = res.BufferedReader()
+	if body == nil {
+		body = strings.NewReader("")
+	}
+	if _, err = io.Copy(wr, body); err != nil {
+		return fmt.Errorf("failed to download: %s", err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("failed to close: %s", err)
+	}
+	return nil
+}
+
+// responseValidator returns the strong ETag or Last-Modified of the response for If-Range
+func responseValidator(header http.Header) string {
+	if etag := header.Get("ETag"); len(etag) != 0 && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// parseContentRange parses "bytes start-end/size", size is -1 if unknown
+func parseContentRange(contentRange string) (start int64, size int64, ok bool) {
+	unit, spec, found := strings.Cut(strings.TrimSpace(contentRange), " ")
+	if !found || unit != "bytes" {
+		return 0, 0, false
+	}
+	rangeSpec, sizeSpec, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	size = -1
+	if sizeSpec != "*" {
+		var err error
+		if size, err = strconv.ParseInt(sizeSpec, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if rangeSpec == "*" {
+		return 0, size, true
+	}
+	startSpec, _, found := strings.Cut(rangeSpec, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startSpec, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+func verifyChecksum(name string, want string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open: %s", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to read: %s", err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return &ChecksumError{Want: want, Got: got}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fileETag is the ETag of the file startFileServer serves
+const fileETag = `"v1"`
+
+func startFileServer(content []byte, ranges *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", fileETag)
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+func TestClient_Download(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 1000))
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name       string
+		partial    []byte
+		validator  string
+		options    []DownloadOption
+		wantRanges []string
+		wantErr    bool
+	}{
+		{
+			name:       "download",
+			options:    []DownloadOption{WithDownloadChecksum(checksum)},
+			wantRanges: []string{""},
+		},
+		{
+			name:       "resume",
+			partial:    content[:1234],
+			validator:  fileETag,
+			wantRanges: []string{"bytes=1234-"},
+		},
+		{
+			name:       "resume - completed",
+			partial:    content,
+			validator:  fileETag,
+			wantRanges: []string{"bytes=10000-"},
+		},
+		{
+			name:       "resume - without validator with checksum",
+			partial:    content[:1234],
+			options:    []DownloadOption{WithDownloadChecksum(checksum)},
+			wantRanges: []string{"bytes=1234-"},
+		},
+		{
+			name:       "resume - without validator",
+			partial:    []byte("OLDOLD"),
+			wantRanges: []string{""},
+		},
+		{
+			name:       "resume - file changed",
+			partial:    []byte("OLDOLD"),
+			validator:  `"v0"`,
+			wantRanges: []string{"bytes=6-"},
+		},
+		{
+			name:       "no resume",
+			options:    []DownloadOption{WithDownloadResume(false)},
+			wantRanges: []string{""},
+		},
+		{
+			name:       "checksum mismatch",
+			options:    []DownloadOption{WithDownloadChecksum(strings.Repeat("0", 64))},
+			wantRanges: []string{""},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			server := startFileServer(content, &ranges)
+			defer server.Close()
+
+			dst := filepath.Join(t.TempDir(), "file.bin")
+			if tt.partial != nil {
+				if err := os.WriteFile(dst+DownloadTempSuffix, tt.partial, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(tt.validator) != 0 {
+				if err := os.WriteFile(dst+DownloadTempSuffix+DownloadValidatorSuffix, []byte(tt.validator), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var lastTransferred, lastTotal int64
+			options := append(tt.options, WithDownloadProgress(func(transferred, total int64) {
+				lastTransferred, lastTotal = transferred, total
+			}))
+			err := NewClient().Download(context.Background(), server.URL, dst, options...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Download() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("Download() ranges got = %q, want %q", ranges, tt.wantRanges)
+			}
+			if tt.wantErr {
+				var checksumErr *ChecksumError
+				if !errors.As(err, &checksumErr) {
+					t.Errorf("Download() want *ChecksumError, got %v", err)
+				}
+				if _, statErr := os.Stat(dst); statErr == nil {
+					t.Errorf("Download() dst should not exist")
+				}
+				return
+			}
+
+			got, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("Download() content mismatch, got %d bytes", len(got))
+			}
+			if lastTransferred != int64(len(content)) || lastTotal != int64(len(content)) {
+				t.Errorf("Download() progress got = %d/%d", lastTransferred, lastTotal)
+			}
+			if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(dst), "*"+DownloadTempSuffix+"*")); len(matches) != 0 {
+				t.Errorf("Download() temp files left: %v", matches)
+			}
+		})
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		contentRange string
+		wantStart    int64
+		wantSize     int64
+		wantOk       bool
+	}{
+		{"bytes 100-199/1000", 100, 1000, true},
+		{"bytes 100-199/*", 100, -1, true},
+		{"bytes */1000", 0, 1000, true},
+		{"items 0-1/2", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := parseContentRange(tt.contentRange)
+		if start != tt.wantStart || size != tt.wantSize || ok != tt.wantOk {
+			t.Errorf("parseContentRange(%q) got = %d, %d, %v", tt.contentRange, start, size, ok)
+		}
+	}
+}
+
+func TestClient_Download_Validator(t *testing.T) {
+	content := []byte("NEWCONTENT-0123456789")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", fileETag)
+		if len(r.Header.Get("Range")) != 0 {
+			http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+			return
+		}
+		// the download without Range is interrupted after a part
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(content[:10])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+
+	dst := filepath.Join(t.TempDir(), "file.bin")
+	if err := NewClient().Download(context.Background(), server.URL, dst); err == nil {
+		t.Fatalf("Download() want error of the interrupted download")
+	}
+	if validator, err := os.ReadFile(dst + DownloadTempSuffix + DownloadValidatorSuffix); err != nil || string(validator) != fileETag {
+		t.Fatalf("Download() validator got = %q %v, want %s", validator, err, fileETag)
+	}
+	if err := NewClient().Download(context.Background(), server.URL, dst); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, content) {
+		t.Errorf("Download() content got = %q, want %q", got, content)
+	}
+}
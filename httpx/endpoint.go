@@ -0,0 +1,182 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Endpoint is a typed API endpoint declared with a method and a path template
+//
+// fields of Req are bound to the request with struct tags
+//
+//	type UpdatePostReq struct {
+//		Id     int      `path:"id"`                // replaces {id} in the path template, escaped
+//		Fields []string `query:"fields,omitempty"` // query param, see WithQueryStruct for options
+//		Token  string   `header:"Authorization"`   // request header
+//		Post   *Post    `body:"json"`              // json request body
+//	}
+//
+// Res is decoded from the response body with the BodyParser of the content type
+type Endpoint[Req any, Res any] struct {
+	client  *Client
+	method  string
+	baseURL string
+	path    string
+	options []ReqOption
+}
+
+// NewEndpoint creates an endpoint of the method and the path template relative to baseURL
+func NewEndpoint[Req any, Res any](client *Client, method string, baseURL string, path string, options ...ReqOption) *Endpoint[Req, Res] {
+	e := &Endpoint[Req, Res]{}
+	e.bind(client, method, baseURL, path, options)
+	return e
+}
+
+func (e *Endpoint[Req, Res]) bind(client *Client, method string, baseURL string, path string, options []ReqOption) {
+	e.client = client
+	e.method = strings.ToUpper(method)
+	e.baseURL = baseURL
+	e.path = path
+	e.options = options
+}
+
+// Method returns the method of the endpoint
+func (e *Endpoint[Req, Res]) Method() string {
+	return e.method
+}
+
+// Path returns the path template of the endpoint
+func (e *Endpoint[Req, Res]) Path() string {
+	return e.path
+}
+
+// Call requests the endpoint with req and decodes the response body into Res
+// non-2xx response is returned as *Problem or *StatusError
+func (e *Endpoint[Req, Res]) Call(ctx context.Context, req Req, options ...ReqOption) (res Res, err error) {
+	if e.client == nil {
+		err = fmt.Errorf("endpoint is not bound")
+		return
+	}
+
+	reqOptions, err := bindRequest(e.path, req)
+	if err != nil {
+		return
+	}
+	callOptions := make([]ReqOption, 0, len(e.options)+len(reqOptions)+len(options)+1)
+	callOptions = append(callOptions, e.options...)
+	callOptions = append(callOptions, reqOptions...)
+	callOptions = append(callOptions, options...)
+	callOptions = append(callOptions, WithContext(ctx))
+
+	baseURL, err := url.Parse(e.baseURL)
+	if err != nil {
+		err = fmt.Errorf("parse error: %v", err)
+		return
+	}
+	resp, err := e.client.Do(e.method, baseURL, callOptions...)
+	if err != nil {
+		return
+	}
+	defer resp.Close()
+
+	if err = resp.statusError(); err != nil {
+		return
+	}
+	// nothing to decode
+	if resp.BufferedReader() == nil || resp.StatusCode() == http.StatusNoContent ||
+		(resp.res.ContentLength == 0 && len(resp.res.Header.Get("Content-Type")) == 0) {
+		return
+	}
+	err = resp.Unmarshal(&res)
+	return
+}
+
+// bindRequest builds options from the tagged fields of req
+func bindRequest(pathTemplate string, req any) ([]ReqOption, error) {
+	options := []ReqOption{
+		WithPathParams(pathTemplate, req),
+		withStructQuery(req, "query"),
+	}
+
+	headerFields, err := taggedFields(req, "header")
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range headerFields {
+		if field.skip() {
+			continue
+		}
+		for _, value := range field.formatValues() {
+			options = append(options, WithHeader(field.name, value))
+		}
+	}
+
+	bodyFields, _ := taggedFields(req, "body")
+	for _, field := range bodyFields {
+		if field.skip() {
+			continue
+		}
+		switch field.name {
+		case "json":
+			options = append(options, WithJsonObject(field.value.Interface()))
+		default:
+			return nil, fmt.Errorf("unsupported body encoding %s", field.name)
+		}
+	}
+	return options, nil
+}
+
+// endpointBinder is implemented by *Endpoint
+type endpointBinder interface {
+	bind(client *Client, method string, baseURL string, path string, options []ReqOption)
+}
+
+// BindAPI binds the Endpoint fields of api, a pointer to struct, declared with `http:"METHOD /path"` tag
+//
+//	type PostAPI struct {
+//		Get    httpx.Endpoint[GetPostReq, Post]   `http:"GET /posts/{id}"`
+//		Create httpx.Endpoint[CreatePostReq, Post] `http:"POST /posts"`
+//	}
+//
+//	var api PostAPI
+//	err := httpx.BindAPI(client, "https://example.com", &api)
+//	post, err := api.Get.Call(ctx, GetPostReq{Id: 1})
+func BindAPI(client *Client, baseURL string, api any, options ...ReqOption) error {
+	val := reflect.ValueOf(api)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%T is not a pointer to struct", api)
+	}
+	val = val.Elem()
+	valType := val.Type()
+
+	for i := 0; i < valType.NumField(); i++ {
+		field := valType.Field(i)
+		tag, ok := field.Tag.Lookup("http")
+		if !ok {
+			continue
+		}
+		method, path, found := strings.Cut(strings.TrimSpace(tag), " ")
+		if !found || len(method) == 0 || !field.IsExported() {
+			return fmt.Errorf("invalid http tag of %s: %s", field.Name, tag)
+		}
+
+		fieldVal := val.Field(i)
+		if fieldVal.Kind() == reflect.Pointer {
+			if fieldVal.IsNil() {
+				fieldVal.Set(reflect.New(field.Type.Elem()))
+			}
+		} else {
+			fieldVal = fieldVal.Addr()
+		}
+		binder, ok := fieldVal.Interface().(endpointBinder)
+		if !ok {
+			return fmt.Errorf("%s is not an Endpoint", field.Name)
+		}
+		binder.bind(client, method, baseURL, strings.TrimSpace(path), options)
+	}
+	return nil
+}
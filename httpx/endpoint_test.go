@@ -0,0 +1,154 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testGetPostReq struct {
+	Id     string   `path:"id"`
+	Fields []string `query:"fields,omitempty"`
+	Token  string   `header:"Authorization,omitempty"`
+}
+
+type testCreatePostReq struct {
+	UserId int       `path:"userId"`
+	Post   *testPost `body:"json"`
+}
+
+type testPostAPI struct {
+	Get    Endpoint[testGetPostReq, testPost]     `http:"GET /posts/{id}"`
+	Create *Endpoint[testCreatePostReq, testPost] `http:"POST /users/{userId}/posts"`
+	Delete Endpoint[testGetPostReq, struct{}]     `http:"DELETE /posts/{id}"`
+}
+
+// startPostServer echoes request path, query and header in the post
+func startPostServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == "/posts/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		post := testPost{Id: 1}
+		if r.Body != nil {
+			json.NewDecoder(r.Body).Decode(&post)
+		}
+		post.Title = r.URL.EscapedPath()
+		post.Body = r.URL.RawQuery + "|" + r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(post)
+	}))
+}
+
+func TestEndpoint_Call(t *testing.T) {
+	server := startPostServer()
+	defer server.Close()
+
+	var api testPostAPI
+	if err := BindAPI(NewClient(), server.URL, &api); err != nil {
+		t.Fatalf("BindAPI() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		call     func() (testPost, error)
+		wantBody testPost
+	}{
+		{
+			name: "path, query and header",
+			call: func() (testPost, error) {
+				return api.Get.Call(context.Background(), testGetPostReq{
+					Id:     "a/b ..",
+					Fields: []string{"title", "body"},
+					Token:  "token",
+				})
+			},
+			wantBody: testPost{Id: 1, Title: "/posts/a%2Fb%20..", Body: "fields=title&fields=body|token"},
+		},
+		{
+			name: "dot segment",
+			call: func() (testPost, error) {
+				return api.Get.Call(context.Background(), testGetPostReq{Id: ".."})
+			},
+			wantBody: testPost{Id: 1, Title: "/posts/%2E%2E", Body: "|"},
+		},
+		{
+			name: "json body",
+			call: func() (testPost, error) {
+				return api.Create.Call(context.Background(), testCreatePostReq{
+					UserId: 7,
+					Post:   &testPost{UserId: 7, Id: 101},
+				})
+			},
+			wantBody: testPost{UserId: 7, Id: 101, Title: "/users/7/posts", Body: "|"},
+		},
+		{
+			name: "NewEndpoint",
+			call: func() (testPost, error) {
+				get := NewEndpoint[testGetPostReq, testPost](NewClient(), "GET", server.URL, "/posts/{id}")
+				return get.Call(context.Background(), testGetPostReq{Id: "1"})
+			},
+			wantBody: testPost{Id: 1, Title: "/posts/1", Body: "|"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("Call() got = %v, want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestEndpoint_CallError(t *testing.T) {
+	server := startPostServer()
+	defer server.Close()
+
+	var api testPostAPI
+	if err := BindAPI(NewClient(), server.URL, &api); err != nil {
+		t.Fatalf("BindAPI() error = %v", err)
+	}
+
+	_, err := api.Get.Call(context.Background(), testGetPostReq{Id: "missing"})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Call() want 404 *StatusError, got %v", err)
+	}
+
+	if _, err = api.Delete.Call(context.Background(), testGetPostReq{Id: "1"}); err != nil {
+		t.Errorf("Call() no content error = %v", err)
+	}
+
+	var unbound Endpoint[testGetPostReq, testPost]
+	if _, err = unbound.Call(context.Background(), testGetPostReq{}); err == nil {
+		t.Errorf("Call() want error for unbound endpoint")
+	}
+}
+
+func TestBindAPI_Invalid(t *testing.T) {
+	var notPointer testPostAPI
+	if err := BindAPI(NewClient(), "", notPointer); err == nil {
+		t.Errorf("BindAPI() want error for non pointer")
+	}
+
+	var invalid struct {
+		Get Endpoint[testGetPostReq, testPost] `http:"/posts"`
+	}
+	if err := BindAPI(NewClient(), "", &invalid); err == nil {
+		t.Errorf("BindAPI() want error for invalid tag")
+	}
+}
@@ -0,0 +1,221 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GraphQLRequest is the body of a GraphQL request
+type GraphQLRequest struct {
+	Query         string         `json:"query,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation is a location in the query of an error
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is an error of the errors array
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+	if len(e.Path) != 0 {
+		path := make([]string, len(e.Path))
+		for i, p := range e.Path {
+			path[i] = fmt.Sprint(p)
+		}
+		return fmt.Sprintf("%s (path: %s)", e.Message, strings.Join(path, "."))
+	}
+	return e.Message
+}
+
+// Code returns the code of the extensions, empty if none
+func (e *GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLErrors is the errors array of a response
+// data can be decoded in part along with the errors
+type GraphQLErrors []*GraphQLError
+
+func (errs GraphQLErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns each error for errors.Is and errors.As
+func (errs GraphQLErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+type GraphQLOption func(graphQLClient *GraphQLClient)
+
+// WithPersistedQueries sends the sha256 hash of the query instead of the query
+// and the query with the hash only when the server has not persisted it yet
+// https://www.apollographql.com/docs/apollo-server/performance/apq
+func WithPersistedQueries(enabled bool) GraphQLOption {
+	return func(graphQLClient *GraphQLClient) {
+		graphQLClient.persisted = enabled
+	}
+}
+
+// WithGraphQLReqOptions adds options to every request, like auth headers
+func WithGraphQLReqOptions(options ...ReqOption) GraphQLOption {
+	return func(graphQLClient *GraphQLClient) {
+		graphQLClient.options = append(graphQLClient.options, options...)
+	}
+}
+
+// GraphQLClient sends GraphQL operations to an endpoint with httpx.Client
+type GraphQLClient struct {
+	client    *Client
+	url       string
+	persisted bool
+	options   []ReqOption
+}
+
+// NewGraphQLClient creates a GraphQLClient for the endpoint url
+func NewGraphQLClient(client *Client, url string, options ...GraphQLOption) *GraphQLClient {
+	g := &GraphQLClient{
+		client: client,
+		url:    url,
+	}
+	for _, opt := range options {
+		opt(g)
+	}
+	return g
+}
+
+// Query sends the query and decodes data into result
+// GraphQLErrors is returned if the response has errors, result has the data decoded in part
+func (g *GraphQLClient) Query(ctx context.Context, query string, variables map[string]any, result any, options ...ReqOption) error {
+	return g.Do(ctx, GraphQLRequest{Query: query, Variables: variables}, result, options...)
+}
+
+// Mutate sends the mutation and decodes data into result
+func (g *GraphQLClient) Mutate(ctx context.Context, mutation string, variables map[string]any, result any, options ...ReqOption) error {
+	return g.Do(ctx, GraphQLRequest{Query: mutation, Variables: variables}, result, options...)
+}
+
+// Do sends the request and decodes data into result, result can be nil
+func (g *GraphQLClient) Do(ctx context.Context, request GraphQLRequest, result any, options ...ReqOption) error {
+	if !g.persisted || len(request.Query) == 0 {
+		return g.do(ctx, request, result, options)
+	}
+
+	hash := sha256.Sum256([]byte(request.Query))
+	extensions := make(map[string]any, len(request.Extensions)+1)
+	for k, v := range request.Extensions {
+		extensions[k] = v
+	}
+	extensions["persistedQuery"] = map[string]any{
+		"version":    1,
+		"sha256Hash": hex.EncodeToString(hash[:]),
+	}
+	request.Extensions = extensions
+
+	// the hash only
+	hashOnly := request
+	hashOnly.Query = ""
+	err := g.do(ctx, hashOnly, result, options)
+	if !isPersistedQueryNotFound(err) {
+		return err
+	}
+	// register the query with the hash
+	return g.do(ctx, request, result, options)
+}
+
+func isPersistedQueryNotFound(err error) bool {
+	errs, ok := err.(GraphQLErrors)
+	if !ok {
+		return false
+	}
+	for _, e := range errs {
+		if e.Code() == "PERSISTED_QUERY_NOT_FOUND" || e.Message == "PersistedQueryNotFound" {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *GraphQLClient) do(ctx context.Context, request GraphQLRequest, result any, options []ReqOption) error {
+	reqOptions := make([]ReqOption, 0, len(g.options)+len(options)+3)
+	reqOptions = append(reqOptions, g.options...)
+	reqOptions = append(reqOptions, options...)
+	reqOptions = append(reqOptions,
+		WithContext(ctx),
+		WithHeader("Accept", "application/graphql-response+json, application/json"),
+		WithJsonObject(request))
+
+	res, err := g.client.Post(g.url, reqOptions...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	var data []byte
+	if reader := res.BufferedReader(); reader != nil {
+		if data, err = io.ReadAll(reader); err != nil {
+			return fmt.Errorf("error while reading: %s", err)
+		}
+	}
+	var body graphQLResponse
+	if err = json.Unmarshal(data, &body); err != nil || (body.Data == nil && body.Errors == nil) {
+		if res.StatusCode() < 200 || res.StatusCode() >= 300 {
+			return &StatusError{StatusCode: res.StatusCode(), Status: res.Status()}
+		}
+		return fmt.Errorf("invalid graphql response: %s", data)
+	}
+
+	if result != nil && len(body.Data) != 0 && !bytes.Equal(body.Data, []byte("null")) {
+		parser := res.getBodyParser("application/json")
+		if parser == nil {
+			parser = JsonBodyParser
+		}
+		if err = parser(bytes.NewReader(body.Data), result); err != nil {
+			return fmt.Errorf("failed to decode data: %s", err)
+		}
+	}
+	if len(body.Errors) != 0 {
+		return body.Errors
+	}
+	if res.StatusCode() < 200 || res.StatusCode() >= 300 {
+		return &StatusError{StatusCode: res.StatusCode(), Status: res.Status()}
+	}
+	return nil
+}
+
+// GraphQLQuery sends the query and returns data decoded into T
+func GraphQLQuery[T any](ctx context.Context, g *GraphQLClient, query string, variables map[string]any, options ...ReqOption) (T, error) {
+	var result T
+	err := g.Query(ctx, query, variables, &result, options...)
+	return result, err
+}
@@ -0,0 +1,114 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func startGraphQLServer(persisted map[string]string, requests *[]GraphQLRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, req)
+		w.Header().Set("Content-Type", "application/json")
+
+		if pq, ok := req.Extensions["persistedQuery"].(map[string]any); ok {
+			hash := pq["sha256Hash"].(string)
+			if len(req.Query) == 0 {
+				if req.Query, ok = persisted[hash]; !ok {
+					w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+					return
+				}
+			}
+			persisted[hash] = req.Query
+		}
+
+		switch req.Query {
+		case "query { user(id: $id) { name } }":
+			w.Write([]byte(`{"data":{"user":{"name":"user` + req.Variables["id"].(string) + `"}}}`))
+		case "mutation { rename }":
+			w.Write([]byte(`{"data":{"rename":true}}`))
+		case "query { partial }":
+			w.Write([]byte(`{"data":{"user":{"name":"a"}},"errors":[` +
+				`{"message":"not allowed","path":["user","email"],"extensions":{"code":"FORBIDDEN"}},` +
+				`{"message":"second","locations":[{"line":1,"column":2}]}]}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("bad gateway"))
+		}
+	}))
+}
+
+type userData struct {
+	User struct {
+		Name string `json:"name"`
+	} `json:"user"`
+}
+
+func TestGraphQLClient(t *testing.T) {
+	var requests []GraphQLRequest
+	server := startGraphQLServer(map[string]string{}, &requests)
+	defer server.Close()
+	g := NewGraphQLClient(NewClient(), server.URL)
+
+	got, err := GraphQLQuery[userData](context.Background(), g, "query { user(id: $id) { name } }", map[string]any{"id": "1"})
+	if err != nil || got.User.Name != "user1" {
+		t.Errorf("GraphQLQuery() got = %v %v, want user1", got, err)
+	}
+
+	var renamed struct {
+		Rename bool `json:"rename"`
+	}
+	if err = g.Mutate(context.Background(), "mutation { rename }", nil, &renamed); err != nil || !renamed.Rename {
+		t.Errorf("Mutate() got = %v %v, want true", renamed, err)
+	}
+
+	var partial userData
+	err = g.Query(context.Background(), "query { partial }", nil, &partial)
+	var errs GraphQLErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Query() error got = %v, want 2 GraphQLErrors", err)
+	}
+	if partial.User.Name != "a" {
+		t.Errorf("Query() partial data got = %v, want a", partial.User.Name)
+	}
+	if errs[0].Code() != "FORBIDDEN" || err.Error() != "graphql: not allowed (path: user.email); second" {
+		t.Errorf("Query() errors got = %v %v", errs[0].Code(), err)
+	}
+
+	if err = g.Query(context.Background(), "query { unknown }", nil, nil); !errors.As(err, new(*StatusError)) {
+		t.Errorf("Query() error got = %v, want *StatusError", err)
+	}
+}
+
+func TestGraphQLClient_PersistedQueries(t *testing.T) {
+	var requests []GraphQLRequest
+	server := startGraphQLServer(map[string]string{}, &requests)
+	defer server.Close()
+	g := NewGraphQLClient(NewClient(), server.URL, WithPersistedQueries(true))
+
+	query := "query { user(id: $id) { name } }"
+	for _, id := range []string{"1", "2"} {
+		got, err := GraphQLQuery[userData](context.Background(), g, query, map[string]any{"id": id})
+		if err != nil || got.User.Name != "user"+id {
+			t.Errorf("GraphQLQuery() got = %v %v, want user%s", got, err, id)
+		}
+	}
+
+	// not found, registered, then the hash only
+	var queries []string
+	for _, req := range requests {
+		queries = append(queries, req.Query)
+	}
+	if want := []string{"", query, ""}; !reflect.DeepEqual(queries, want) {
+		t.Errorf("requests got = %q, want %q", queries, want)
+	}
+}
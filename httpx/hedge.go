@@ -0,0 +1,185 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type hedgeResult struct {
+	index int
+	res   *Response
+	err   error
+}
+
+func (r hedgeResult) succeeded() bool {
+	return r.err == nil && r.res.StatusCode() < http.StatusInternalServerError
+}
+
+// Hedge sends GET to urls one by one every delay until one of them succeeds
+// the next url is requested right away when an attempt fails
+// the first response with status less than 500 is returned and the others are canceled
+// if all the attempts fail, the first failed response or the first error is returned
+//
+// pass the same url more than once to hedge against a single backend
+func (c *Client) Hedge(ctx context.Context, urls []string, delay time.Duration, options ...ReqOption) (res *Response, err error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no url to hedge")
+	}
+
+	results := make(chan hedgeResult, len(urls))
+	cancels := make([]context.CancelFunc, len(urls))
+	launched := 0
+	pending := 0
+	launch := func() {
+		index := launched
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels[index] = cancel
+		attemptOptions := append(append([]ReqOption{}, options...), WithContext(attemptCtx))
+		go func() {
+			res, err := c.Get(urls[index], attemptOptions...)
+			results <- hedgeResult{index: index, res: res, err: err}
+		}()
+		launched++
+		pending++
+	}
+
+	// cancel and close the attempts other than the returned one
+	finish := func(keep int) {
+		for i := 0; i < launched; i++ {
+			if i != keep {
+				cancels[i]()
+			}
+		}
+		go func(pending int) {
+			for ; pending > 0; pending-- {
+				if r := <-results; r.res != nil {
+					r.res.Close()
+				}
+			}
+		}(pending)
+	}
+
+	launch()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var failed []hedgeResult
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if launched < len(urls) {
+				launch()
+				timer.Reset(delay)
+			}
+		case r := <-results:
+			pending--
+			if r.succeeded() {
+				r.res.cancel = cancels[r.index]
+				finish(r.index)
+				for _, f := range failed {
+					if f.res != nil {
+						f.res.Close()
+					}
+				}
+				return r.res, nil
+			}
+			failed = append(failed, r)
+			if launched < len(urls) {
+				launch()
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(delay)
+			}
+		case <-ctx.Done():
+			finish(-1)
+			for _, f := range failed {
+				if f.res != nil {
+					f.res.Close()
+				}
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	// all failed, return the first response or the first error
+	keep := -1
+	for _, f := range failed {
+		if keep < 0 && f.res != nil {
+			keep = f.index
+			res = f.res
+			res.cancel = cancels[f.index]
+			continue
+		}
+		if f.res != nil {
+			f.res.Close()
+		}
+	}
+	finish(keep)
+	if res != nil {
+		return res, nil
+	}
+	return nil, failed[0].err
+}
+
+// BatchRequest is a request run by Batch
+type BatchRequest struct {
+	Method  string
+	URL     string
+	Options []ReqOption
+}
+
+// BatchResult is the result of a BatchRequest
+// Response should be closed if not nil
+type BatchResult struct {
+	Response *Response
+	Err      error
+}
+
+// Batch runs the requests with at most concurrency requests in flight
+// results are in the order of the requests
+// requests not started when ctx is done have ctx error
+func (c *Client) Batch(ctx context.Context, requests []BatchRequest, concurrency int) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = len(requests)
+	}
+	results := make([]BatchResult, len(requests))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := range requests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			request := requests[i]
+			u, err := url.Parse(request.URL)
+			if err != nil {
+				results[i].Err = fmt.Errorf("parse error: %v", err)
+				return
+			}
+			options := append(append([]ReqOption{}, request.Options...), WithContext(ctx))
+			results[i].Response, results[i].Err = c.Do(request.Method, u, options...)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
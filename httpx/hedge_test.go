@@ -0,0 +1,170 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startDelayServer responds name after delay, canceled counts the requests canceled by the client
+func startDelayServer(name string, status int, delay time.Duration, canceled *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			if canceled != nil {
+				atomic.AddInt32(canceled, 1)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		w.Write([]byte(name))
+	}))
+}
+
+func TestClient_Hedge(t *testing.T) {
+	var slowCanceled int32
+	slow := startDelayServer("slow", http.StatusOK, time.Second, &slowCanceled)
+	defer slow.Close()
+	fast := startDelayServer("fast", http.StatusOK, 0, nil)
+	defer fast.Close()
+	broken := startDelayServer("broken", http.StatusServiceUnavailable, 0, nil)
+	defer broken.Close()
+
+	tests := []struct {
+		name         string
+		urls         []string
+		want         string
+		wantStatus   int
+		wantCanceled int32
+		// delay is the hedge delay, maxDuration is well below it if the next url is not to wait for it
+		delay       time.Duration
+		maxDuration time.Duration
+	}{
+		{
+			name:         "slow then fast",
+			urls:         []string{slow.URL, fast.URL},
+			want:         "fast",
+			wantStatus:   http.StatusOK,
+			wantCanceled: 1,
+			delay:        50 * time.Millisecond,
+			maxDuration:  500 * time.Millisecond,
+		},
+		{
+			name:        "broken then fast without delay",
+			urls:        []string{broken.URL, fast.URL},
+			want:        "fast",
+			wantStatus:  http.StatusOK,
+			delay:       5 * time.Second,
+			maxDuration: 2 * time.Second,
+		},
+		{
+			name:        "all broken",
+			urls:        []string{broken.URL, broken.URL},
+			want:        "broken",
+			wantStatus:  http.StatusServiceUnavailable,
+			delay:       5 * time.Second,
+			maxDuration: 2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&slowCanceled, 0)
+			start := time.Now()
+			res, err := NewClient().Hedge(context.Background(), tt.urls, tt.delay,
+				WithBodyParser("text/plain", TextBodyParser))
+			if err != nil {
+				t.Fatalf("Hedge() error = %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > tt.maxDuration {
+				t.Errorf("Hedge() took %v, want less than %v", elapsed, tt.maxDuration)
+			}
+			if res.StatusCode() != tt.wantStatus {
+				t.Errorf("Hedge() status got = %d, want %d", res.StatusCode(), tt.wantStatus)
+			}
+			var got string
+			if err = res.Unmarshal(&got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Hedge() got = %v, want %v", got, tt.want)
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for atomic.LoadInt32(&slowCanceled) != tt.wantCanceled && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := atomic.LoadInt32(&slowCanceled); got != tt.wantCanceled {
+				t.Errorf("Hedge() canceled got = %d, want %d", got, tt.wantCanceled)
+			}
+		})
+	}
+}
+
+func TestClient_HedgeContext(t *testing.T) {
+	slow := startDelayServer("slow", http.StatusOK, time.Second, nil)
+	defer slow.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := NewClient().Hedge(ctx, []string{slow.URL, slow.URL}, 10*time.Millisecond); err != context.DeadlineExceeded {
+		t.Errorf("Hedge() error got = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClient_Batch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	var requests []BatchRequest
+	for i := 0; i < 10; i++ {
+		requests = append(requests, BatchRequest{
+			Method:  "GET",
+			URL:     fmt.Sprintf("%s/%d", server.URL, i),
+			Options: []ReqOption{WithBodyParser("text/plain", TextBodyParser)},
+		})
+	}
+	requests = append(requests, BatchRequest{Method: "GET", URL: "http://[::1"})
+
+	results := NewClient().Batch(context.Background(), requests, 3)
+	if len(results) != len(requests) {
+		t.Fatalf("Batch() len got = %d, want %d", len(results), len(requests))
+	}
+	for i, result := range results[:10] {
+		if result.Err != nil {
+			t.Errorf("Batch() [%d] error = %v", i, result.Err)
+			continue
+		}
+		var got string
+		if err := result.Response.Unmarshal(&got); err != nil {
+			t.Errorf("Batch() [%d] unmarshal error = %v", i, err)
+		}
+		if want := fmt.Sprintf("/%d", i); got != want {
+			t.Errorf("Batch() [%d] got = %v, want %v", i, got, want)
+		}
+	}
+	if results[10].Err == nil {
+		t.Errorf("Batch() want error for invalid url")
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max > 3 {
+		t.Errorf("Batch() max in flight got = %d, want <= 3", max)
+	}
+}
@@ -0,0 +1,45 @@
+package httpx
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// IdempotencyKeyHeader is the header of the idempotency key
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sets the Idempotency-Key header
+// the key is the same for all the retries, so POST and PATCH are retried as well
+func WithIdempotencyKey(key string) ReqOption {
+	return func(req *Request) error {
+		if len(key) == 0 {
+			return fmt.Errorf("empty idempotency key")
+		}
+		req.headers[IdempotencyKeyHeader] = []string{key}
+		req.idempotent = true
+		return nil
+	}
+}
+
+// WithIdempotency sets the Idempotency-Key header with a random UUID generated for each request
+func WithIdempotency() ReqOption {
+	return func(req *Request) error {
+		key, err := newUUID()
+		if err != nil {
+			return fmt.Errorf("failed to create idempotency key: %s", err)
+		}
+		return WithIdempotencyKey(key)(req)
+	}
+}
+
+// newUUID returns a random UUID version 4
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
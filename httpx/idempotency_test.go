@@ -0,0 +1,93 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		// the first attempt of each request fails
+		if len(keys)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	tests := []struct {
+		name       string
+		options    []ReqOption
+		wantStatus int
+		wantKeys   int
+		check      func(key string) bool
+	}{
+		{
+			name:       "post without key is not retried",
+			wantStatus: http.StatusServiceUnavailable,
+			wantKeys:   1,
+			check:      func(key string) bool { return key == "" },
+		},
+		{
+			name:       "supplied key",
+			options:    []ReqOption{WithIdempotencyKey("order-1")},
+			wantStatus: http.StatusOK,
+			wantKeys:   2,
+			check:      func(key string) bool { return key == "order-1" },
+		},
+		{
+			name:       "generated key",
+			options:    []ReqOption{WithIdempotency()},
+			wantStatus: http.StatusOK,
+			wantKeys:   2,
+			check:      uuid.MatchString,
+		},
+		{
+			name:       "removed key is not retried",
+			options:    []ReqOption{WithIdempotency(), WithoutHeader(IdempotencyKeyHeader)},
+			wantStatus: http.StatusServiceUnavailable,
+			wantKeys:   1,
+			check:      func(key string) bool { return key == "" },
+		},
+	}
+	client := NewClient(WithRetries(2))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys = nil
+			res, err := client.Post(server.URL, append(tt.options, WithJsonString(`{}`))...)
+			if err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+			res.Close()
+			if res.StatusCode() != tt.wantStatus {
+				t.Errorf("Post() status got = %d, want %d", res.StatusCode(), tt.wantStatus)
+			}
+			if len(keys) != tt.wantKeys {
+				t.Fatalf("Post() attempts got = %d, want %d", len(keys), tt.wantKeys)
+			}
+			for _, key := range keys {
+				if key != keys[0] || !tt.check(key) {
+					t.Errorf("Post() keys got = %v", keys)
+					break
+				}
+			}
+		})
+	}
+
+	if _, err := client.Post(server.URL, WithIdempotencyKey("")); err == nil {
+		t.Errorf("Post() want error for empty key")
+	}
+}
+
+func TestWithIdempotency_Unique(t *testing.T) {
+	a, b := newRequest(), newRequest()
+	WithIdempotency()(a)
+	WithIdempotency()(b)
+	if a.headers[IdempotencyKeyHeader][0] == b.headers[IdempotencyKeyHeader][0] {
+		t.Errorf("WithIdempotency() got the same key %v", a.headers[IdempotencyKeyHeader])
+	}
+}
@@ -0,0 +1,219 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// error codes of JSON-RPC 2.0
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+)
+
+// RPCError is the error object of a JSON-RPC response
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+	// nil for notifications
+	ID *int64 `json:"id,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// JSONRPCCall is a call of a batch
+type JSONRPCCall struct {
+	Method string
+	// Params is an array or an object, nil to omit
+	Params any
+	// Result is decoded from the result of the response, can be nil
+	Result any
+	// Notify sends the call without id and expects no response
+	Notify bool
+	// Err is the error of the call, *RPCError for an error object
+	Err error
+}
+
+type JSONRPCOption func(jsonRPCClient *JSONRPCClient)
+
+// WithJSONRPCReqOptions adds options to every request, like auth headers
+func WithJSONRPCReqOptions(options ...ReqOption) JSONRPCOption {
+	return func(jsonRPCClient *JSONRPCClient) {
+		jsonRPCClient.options = append(jsonRPCClient.options, options...)
+	}
+}
+
+// JSONRPCClient sends JSON-RPC 2.0 requests to an endpoint with httpx.Client
+// https://www.jsonrpc.org/specification
+type JSONRPCClient struct {
+	client  *Client
+	url     string
+	options []ReqOption
+	lastID  int64
+}
+
+// NewJSONRPCClient creates a JSONRPCClient for the endpoint url
+func NewJSONRPCClient(client *Client, url string, options ...JSONRPCOption) *JSONRPCClient {
+	j := &JSONRPCClient{
+		client: client,
+		url:    url,
+	}
+	for _, opt := range options {
+		opt(j)
+	}
+	return j
+}
+
+func (j *JSONRPCClient) newID() *int64 {
+	id := atomic.AddInt64(&j.lastID, 1)
+	return &id
+}
+
+// Call calls the method and decodes the result into result, result can be nil
+// the error object of the response is returned as *RPCError
+func (j *JSONRPCClient) Call(ctx context.Context, method string, params any, result any, options ...ReqOption) error {
+	call := &JSONRPCCall{Method: method, Params: params, Result: result}
+	if err := j.Batch(ctx, []*JSONRPCCall{call}, options...); err != nil {
+		return err
+	}
+	return call.Err
+}
+
+// Notify sends the notification, no response is expected
+func (j *JSONRPCClient) Notify(ctx context.Context, method string, params any, options ...ReqOption) error {
+	return j.Batch(ctx, []*JSONRPCCall{{Method: method, Params: params, Notify: true}}, options...)
+}
+
+// Batch sends the calls in a batch and sets Result or Err of each call by the id
+// a single call is sent as it is, not in an array
+// the error of the request itself is returned
+func (j *JSONRPCClient) Batch(ctx context.Context, calls []*JSONRPCCall, options ...ReqOption) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	requests := make([]rpcRequest, len(calls))
+	pending := make(map[int64]*JSONRPCCall)
+	for i, call := range calls {
+		requests[i] = rpcRequest{JSONRPC: "2.0", Method: call.Method, Params: call.Params}
+		if !call.Notify {
+			requests[i].ID = j.newID()
+			pending[*requests[i].ID] = call
+		}
+	}
+	var body any = requests
+	if len(requests) == 1 {
+		body = requests[0]
+	}
+
+	reqOptions := make([]ReqOption, 0, len(j.options)+len(options)+3)
+	reqOptions = append(reqOptions, j.options...)
+	reqOptions = append(reqOptions, options...)
+	reqOptions = append(reqOptions,
+		WithContext(ctx),
+		WithHeader("Accept", "application/json"),
+		WithJsonObject(body))
+
+	res, err := j.client.Post(j.url, reqOptions...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	var data []byte
+	if reader := res.BufferedReader(); reader != nil {
+		if data, err = io.ReadAll(reader); err != nil {
+			return fmt.Errorf("error while reading: %s", err)
+		}
+	}
+	data = bytes.TrimSpace(data)
+	if len(pending) == 0 && len(data) == 0 && res.StatusCode() < 300 {
+		return nil
+	}
+
+	var responses []rpcResponse
+	if len(data) != 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &responses)
+	} else {
+		var single rpcResponse
+		if err = json.Unmarshal(data, &single); err == nil {
+			responses = append(responses, single)
+		}
+	}
+	if err != nil || len(responses) == 0 {
+		if res.StatusCode() < 200 || res.StatusCode() >= 300 {
+			return &StatusError{StatusCode: res.StatusCode(), Status: res.Status()}
+		}
+		return fmt.Errorf("invalid jsonrpc response: %s", data)
+	}
+
+	parser := res.getBodyParser("application/json")
+	if parser == nil {
+		parser = JsonBodyParser
+	}
+	for _, response := range responses {
+		// an error without id is for the whole request like a parse error
+		if len(response.ID) == 0 || bytes.Equal(response.ID, []byte("null")) {
+			if response.Error == nil {
+				return fmt.Errorf("invalid jsonrpc response without id")
+			}
+			for _, call := range pending {
+				call.Err = response.Error
+			}
+			return nil
+		}
+		var id int64
+		if err = json.Unmarshal(response.ID, &id); err != nil {
+			return fmt.Errorf("invalid jsonrpc response id: %s", response.ID)
+		}
+		call, ok := pending[id]
+		if !ok {
+			return fmt.Errorf("unexpected jsonrpc response id: %d", id)
+		}
+		delete(pending, id)
+
+		if response.Error != nil {
+			call.Err = response.Error
+			continue
+		}
+		if call.Result != nil && len(response.Result) != 0 {
+			if err = parser(bytes.NewReader(response.Result), call.Result); err != nil {
+				call.Err = fmt.Errorf("failed to decode result: %s", err)
+			}
+		}
+	}
+	for _, call := range pending {
+		call.Err = fmt.Errorf("no response for %s", call.Method)
+	}
+	return nil
+}
+
+// JSONRPCCallAs calls the method and returns the result decoded into T
+func JSONRPCCallAs[T any](ctx context.Context, j *JSONRPCClient, method string, params any, options ...ReqOption) (T, error) {
+	var result T
+	err := j.Call(ctx, method, params, &result, options...)
+	return result, err
+}
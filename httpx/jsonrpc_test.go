@@ -0,0 +1,141 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type testRPCRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+func startJSONRPCServer(notified *int32) *httptest.Server {
+	handle := func(req testRPCRequest) map[string]any {
+		if req.ID == nil {
+			atomic.AddInt32(notified, 1)
+			return nil
+		}
+		res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "add":
+			var params []int
+			json.Unmarshal(req.Params, &params)
+			res["result"] = params[0] + params[1]
+		case "echo":
+			res["result"] = req.Params
+		case "fail":
+			res["error"] = map[string]any{"code": -32000, "message": "failed", "data": map[string]any{"reason": "test"}}
+		default:
+			res["error"] = map[string]any{"code": RPCMethodNotFound, "message": "Method not found"}
+		}
+		return res
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/parse" {
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`))
+			return
+		}
+		var raw json.RawMessage
+		json.NewDecoder(r.Body).Decode(&raw)
+		if raw[0] != '[' {
+			var req testRPCRequest
+			json.Unmarshal(raw, &req)
+			if res := handle(req); res != nil {
+				json.NewEncoder(w).Encode(res)
+			} else {
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		var reqs []testRPCRequest
+		json.Unmarshal(raw, &reqs)
+		var responses []map[string]any
+		// in reverse order to test id correlation
+		for i := len(reqs) - 1; i >= 0; i-- {
+			if res := handle(reqs[i]); res != nil {
+				responses = append(responses, res)
+			}
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+}
+
+func TestJSONRPCClient_Call(t *testing.T) {
+	var notified int32
+	server := startJSONRPCServer(&notified)
+	defer server.Close()
+	j := NewJSONRPCClient(NewClient(), server.URL)
+
+	sum, err := JSONRPCCallAs[int](context.Background(), j, "add", []int{1, 2})
+	if err != nil || sum != 3 {
+		t.Errorf("Call() got = %v %v, want 3", sum, err)
+	}
+
+	var echoed map[string]string
+	if err = j.Call(context.Background(), "echo", map[string]string{"a": "b"}, &echoed); err != nil || echoed["a"] != "b" {
+		t.Errorf("Call() got = %v %v, want map[a:b]", echoed, err)
+	}
+
+	err = j.Call(context.Background(), "fail", nil, nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32000 || string(rpcErr.Data) != `{"reason":"test"}` {
+		t.Errorf("Call() error got = %v, want -32000 with data", err)
+	}
+
+	if err = j.Notify(context.Background(), "log", []string{"x"}); err != nil || atomic.LoadInt32(&notified) != 1 {
+		t.Errorf("Notify() got = %v, notified %d", err, notified)
+	}
+}
+
+func TestJSONRPCClient_Batch(t *testing.T) {
+	var notified int32
+	server := startJSONRPCServer(&notified)
+	defer server.Close()
+	j := NewJSONRPCClient(NewClient(), server.URL)
+
+	var sum int
+	var echoed []string
+	calls := []*JSONRPCCall{
+		{Method: "add", Params: []int{2, 3}, Result: &sum},
+		{Method: "log", Notify: true},
+		{Method: "unknown"},
+		{Method: "echo", Params: []string{"x"}, Result: &echoed},
+	}
+	if err := j.Batch(context.Background(), calls); err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	if calls[0].Err != nil || sum != 5 {
+		t.Errorf("Batch() add got = %v %v, want 5", sum, calls[0].Err)
+	}
+	var rpcErr *RPCError
+	if !errors.As(calls[2].Err, &rpcErr) || rpcErr.Code != RPCMethodNotFound {
+		t.Errorf("Batch() unknown error got = %v, want method not found", calls[2].Err)
+	}
+	if calls[3].Err != nil || len(echoed) != 1 || echoed[0] != "x" {
+		t.Errorf("Batch() echo got = %v %v, want [x]", echoed, calls[3].Err)
+	}
+	if atomic.LoadInt32(&notified) != 1 {
+		t.Errorf("Batch() notified got = %d, want 1", notified)
+	}
+}
+
+func TestJSONRPCClient_ParseError(t *testing.T) {
+	var notified int32
+	server := startJSONRPCServer(&notified)
+	defer server.Close()
+	j := NewJSONRPCClient(NewClient(), server.URL+"/parse")
+
+	err := j.Call(context.Background(), "add", []int{1, 2}, nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != RPCParseError {
+		t.Errorf("Call() error got = %v, want parse error", err)
+	}
+}
@@ -0,0 +1,68 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+)
+
+// ResponseTooLargeError is returned when the response body exceeds the max response size
+type ResponseTooLargeError struct {
+	Limit int64
+	// ContentLength is -1 if unknown
+	ContentLength int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.ContentLength >= 0 {
+		return fmt.Sprintf("response body of %d bytes exceeds %d bytes", e.ContentLength, e.Limit)
+	}
+	return fmt.Sprintf("response body exceeds %d bytes", e.Limit)
+}
+
+// WithDefaultMaxResponseSize limits the response body of every request to limit bytes
+// 0 or negative is no limit(default)
+func WithDefaultMaxResponseSize(limit int64) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.maxResponseSize = limit
+	}
+}
+
+// WithMaxResponseSize limits the response body to limit bytes, overriding the client default
+// negative is no limit
+func WithMaxResponseSize(limit int64) ReqOption {
+	return func(req *Request) error {
+		req.maxResponseSize = limit
+		return nil
+	}
+}
+
+// limitReadCloser fails with *ResponseTooLargeError when more than limit bytes are read
+type limitReadCloser struct {
+	rc    io.ReadCloser
+	limit int64
+	read  int64
+	err   error
+}
+
+func (l *limitReadCloser) Read(p []byte) (n int, err error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	// read one more byte than the limit to know it is exceeded
+	if remain := l.limit + 1 - l.read; int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err = l.rc.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		n -= int(l.read - l.limit)
+		l.read = l.limit
+		l.err = &ResponseTooLargeError{Limit: l.limit, ContentLength: -1}
+		return n, l.err
+	}
+	return n, err
+}
+
+func (l *limitReadCloser) Close() error {
+	return l.rc.Close()
+}
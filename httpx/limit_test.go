@@ -0,0 +1,106 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"name":"` + strings.Repeat("a", 100) + `"}`
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("chunked") == "" {
+			w.Header().Set("Content-Length", "111")
+		}
+		w.Write([]byte(body))
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		client    *Client
+		url       string
+		options   []ReqOption
+		wantLimit int64
+	}{
+		{
+			name:      "content length over client limit",
+			client:    NewClient(WithDefaultMaxResponseSize(50)),
+			url:       server.URL,
+			wantLimit: 50,
+		},
+		{
+			name:      "chunked over request limit",
+			client:    NewClient(),
+			url:       server.URL + "?chunked=1",
+			options:   []ReqOption{WithMaxResponseSize(20)},
+			wantLimit: 20,
+		},
+		{
+			name:    "request disables client limit",
+			client:  NewClient(WithDefaultMaxResponseSize(50)),
+			url:     server.URL + "?chunked=1",
+			options: []ReqOption{WithMaxResponseSize(-1)},
+		},
+		{
+			name:   "exactly the limit",
+			client: NewClient(WithDefaultMaxResponseSize(111)),
+			url:    server.URL + "?chunked=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Name string `json:"name"`
+			}
+			res, err := tt.client.Get(tt.url, tt.options...)
+			if err == nil {
+				err = res.Unmarshal(&got)
+			}
+			var tooLarge *ResponseTooLargeError
+			if tt.wantLimit == 0 {
+				if err != nil {
+					t.Fatalf("Unmarshal() error = %v", err)
+				}
+				if len(got.Name) != 100 {
+					t.Errorf("Unmarshal() name len got = %d, want 100", len(got.Name))
+				}
+				return
+			}
+			if !errors.As(err, &tooLarge) {
+				t.Fatalf("error got = %v, want *ResponseTooLargeError", err)
+			}
+			if tooLarge.Limit != tt.wantLimit {
+				t.Errorf("Limit got = %d, want %d", tooLarge.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestWithMaxResponseSize_BufferedReader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 64)))
+		w.(http.Flusher).Flush()
+	}))
+	defer server.Close()
+
+	res, err := NewClient().Get(server.URL, WithMaxResponseSize(10))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer res.Close()
+	data, err := io.ReadAll(res.BufferedReader())
+	if len(data) != 10 {
+		t.Errorf("ReadAll() len got = %d, want 10", len(data))
+	}
+	if !errors.As(err, new(*ResponseTooLargeError)) {
+		t.Errorf("ReadAll() error got = %v, want *ResponseTooLargeError", err)
+	}
+}
@@ -0,0 +1,290 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorClass classifies the failure of a request
+type ErrorClass string
+
+const (
+	ErrorNone     ErrorClass = ""
+	ErrorCanceled ErrorClass = "canceled"
+	ErrorTimeout  ErrorClass = "timeout"
+	ErrorNetwork  ErrorClass = "network"
+	// ErrorClient is a response with 4xx status
+	ErrorClient ErrorClass = "client"
+	// ErrorServer is a response with 5xx status
+	ErrorServer ErrorClass = "server"
+)
+
+// classifyError returns the class of the result of a request
+func classifyError(err error, statusCode int) ErrorClass {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, context.Canceled):
+			return ErrorCanceled
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrorTimeout
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return ErrorTimeout
+		}
+		return ErrorNetwork
+	}
+	switch {
+	case statusCode >= 500:
+		return ErrorServer
+	case statusCode >= 400:
+		return ErrorClient
+	}
+	return ErrorNone
+}
+
+// RequestMetrics is the measurement of a request
+// every attempt of a retried request is a request
+type RequestMetrics struct {
+	Method string
+	Host   string
+	// Route is set by WithRoute, or the templates of WithPathParams
+	// empty if none of them, the url path is not used so that ids in paths do not grow the labels
+	Route string
+	// StatusCode is 0 if no response
+	StatusCode int
+	// Duration is the time until the response headers are received
+	Duration time.Duration
+	// BytesIn is the response body read until closed
+	BytesIn int64
+	// BytesOut is the request body
+	BytesOut   int64
+	ErrorClass ErrorClass
+}
+
+// MetricsCollector is notified of every request made by the client
+// the request is notified when the response is closed or when it fails without response
+// Observe can be called concurrently
+type MetricsCollector interface {
+	Observe(metrics RequestMetrics)
+}
+
+// WithMetrics sets the collector notified of the requests
+// the route of a request is set by WithRoute or WithPathParams, empty otherwise
+func WithMetrics(collector MetricsCollector) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.metrics = collector
+	}
+}
+
+// WithRoute sets the route reported to MetricsCollector, like /users/{id}
+// requests to urls with ids need it or WithPathParams for the metrics per route
+func WithRoute(route string) ReqOption {
+	return func(req *Request) error {
+		req.route = route
+		return nil
+	}
+}
+
+// observe notifies the collector of the attempt
+// with response, it is notified when the body is closed
+func (c *Client) observe(hreq *http.Request, req *Request, start time.Time, resp *http.Response, err error) {
+	metrics := RequestMetrics{
+		Method:   hreq.Method,
+		Host:     hreq.URL.Host,
+		Route:    req.route,
+		Duration: time.Since(start),
+		BytesOut: hreq.ContentLength,
+	}
+	if len(metrics.Route) == 0 {
+		metrics.Route = req.pathTemplate
+	}
+	if metrics.BytesOut < 0 {
+		metrics.BytesOut = 0
+	}
+	if err != nil || resp == nil {
+		metrics.ErrorClass = classifyError(err, 0)
+		c.metrics.Observe(metrics)
+		return
+	}
+	metrics.StatusCode = resp.StatusCode
+	metrics.ErrorClass = classifyError(nil, resp.StatusCode)
+	resp.Body = &metricsBody{
+		ReadCloser: resp.Body,
+		collector:  c.metrics,
+		metrics:    metrics,
+	}
+}
+
+// metricsBody counts the bytes read and notifies the collector on close
+type metricsBody struct {
+	io.ReadCloser
+	collector MetricsCollector
+	metrics   RequestMetrics
+	once      sync.Once
+}
+
+func (b *metricsBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.metrics.BytesIn += int64(n)
+	return
+}
+
+func (b *metricsBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.collector.Observe(b.metrics)
+	})
+	return err
+}
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the latency histogram
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PromMetrics is an in-process MetricsCollector exposed in Prometheus text format
+//
+//	httpx_requests_total{method,host,route,status,error}
+//	httpx_request_duration_seconds{method,host,route}
+//	httpx_request_bytes_total{method,host,route}
+//	httpx_response_bytes_total{method,host,route}
+type PromMetrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[string]float64
+	latencies map[string]*histogram
+	bytesOut  map[string]float64
+	bytesIn   map[string]float64
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewPromMetrics creates PromMetrics with the latency buckets, DefaultLatencyBuckets if empty
+func NewPromMetrics(buckets ...float64) *PromMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &PromMetrics{
+		buckets:   buckets,
+		requests:  make(map[string]float64),
+		latencies: make(map[string]*histogram),
+		bytesOut:  make(map[string]float64),
+		bytesIn:   make(map[string]float64),
+	}
+}
+
+func (m *PromMetrics) Observe(metrics RequestMetrics) {
+	route := formatLabels("method", metrics.Method, "host", metrics.Host, "route", metrics.Route)
+	status := ""
+	if metrics.StatusCode != 0 {
+		status = strconv.Itoa(metrics.StatusCode)
+	}
+	request := formatLabels("method", metrics.Method, "host", metrics.Host, "route", metrics.Route,
+		"status", status, "error", string(metrics.ErrorClass))
+	seconds := metrics.Duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[request]++
+	m.bytesOut[route] += float64(metrics.BytesOut)
+	m.bytesIn[route] += float64(metrics.BytesIn)
+	h, ok := m.latencies[route]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[route] = h
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// WriteTo writes the metrics in Prometheus text exposition format
+func (m *PromMetrics) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+
+	m.mu.Lock()
+	writeCounter(&sb, "httpx_requests_total", "Total number of requests.", m.requests)
+	sb.WriteString("# HELP httpx_request_duration_seconds Request latency until response headers.\n")
+	sb.WriteString("# TYPE httpx_request_duration_seconds histogram\n")
+	for _, labels := range sortedKeys(m.latencies) {
+		h := m.latencies[labels]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&sb, "httpx_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(&sb, "httpx_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(&sb, "httpx_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.sum))
+		fmt.Fprintf(&sb, "httpx_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+	writeCounter(&sb, "httpx_request_bytes_total", "Total bytes of request bodies.", m.bytesOut)
+	writeCounter(&sb, "httpx_response_bytes_total", "Total bytes of response bodies read.", m.bytesIn)
+	m.mu.Unlock()
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics to be scraped
+func (m *PromMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+func writeCounter(sb *strings.Builder, name, help string, values map[string]float64) {
+	fmt.Fprintf(sb, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(sb, "# TYPE %s counter\n", name)
+	for _, labels := range sortedKeys(values) {
+		fmt.Fprintf(sb, "%s{%s} %s\n", name, labels, formatFloat(values[labels]))
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels formats name, value pairs as name="value",...
+func formatLabels(pairs ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pairs[i])
+		sb.WriteString(`="`)
+		sb.WriteString(labelEscaper.Replace(pairs[i+1]))
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,157 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingCollector struct {
+	mu      sync.Mutex
+	metrics []RequestMetrics
+}
+
+func (c *recordingCollector) Observe(metrics RequestMetrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = append(c.metrics, metrics)
+}
+
+func TestWithMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		options []ReqOption
+		want    RequestMetrics
+	}{
+		{
+			name:    "route template",
+			method:  http.MethodPost,
+			url:     server.URL,
+			options: []ReqOption{WithPathParams("/users/{id}", map[string]int{"id": 1}), WithString("text/plain", "abc")},
+			want: RequestMetrics{Method: "POST", Host: host, Route: "/users/{id}", StatusCode: 200,
+				BytesIn: 5, BytesOut: 3},
+		},
+		{
+			name:    "explicit route",
+			method:  http.MethodGet,
+			url:     server.URL + "/users/1",
+			options: []ReqOption{WithRoute("users")},
+			want:    RequestMetrics{Method: "GET", Host: host, Route: "users", StatusCode: 200, BytesIn: 5},
+		},
+		{
+			name:   "no route and status class",
+			method: http.MethodGet,
+			url:    server.URL + "/missing",
+			want: RequestMetrics{Method: "GET", Host: host, StatusCode: 404,
+				ErrorClass: ErrorClient},
+		},
+		{
+			name:    "path is not a route",
+			method:  http.MethodGet,
+			url:     server.URL,
+			options: []ReqOption{WithPath("/users/1")},
+			want:    RequestMetrics{Method: "GET", Host: host, StatusCode: 200, BytesIn: 5},
+		},
+		{
+			name:   "network error",
+			method: http.MethodGet,
+			url:    "http://127.0.0.1:1/down",
+			want:   RequestMetrics{Method: "GET", Host: "127.0.0.1:1", ErrorClass: ErrorNetwork},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &recordingCollector{}
+			client := NewClient(WithMetrics(collector))
+			u, _ := url.Parse(tt.url)
+			res, err := client.Do(tt.method, u, tt.options...)
+			if err == nil {
+				io.ReadAll(res.BufferedReader())
+				res.Close()
+			}
+			if len(collector.metrics) != 1 {
+				t.Fatalf("Observe() calls got = %d, want 1", len(collector.metrics))
+			}
+			got := collector.metrics[0]
+			if got.Duration <= 0 {
+				t.Errorf("Observe() duration got = %v, want > 0", got.Duration)
+			}
+			got.Duration = 0
+			if got != tt.want {
+				t.Errorf("Observe() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		want       ErrorClass
+	}{
+		{name: "ok", statusCode: 200, want: ErrorNone},
+		{name: "server", statusCode: 503, want: ErrorServer},
+		{name: "canceled", err: context.Canceled, want: ErrorCanceled},
+		{name: "deadline", err: context.DeadlineExceeded, want: ErrorTimeout},
+		{name: "other", err: errors.New("refused"), want: ErrorNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err, tt.statusCode); got != tt.want {
+				t.Errorf("classifyError() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromMetrics(t *testing.T) {
+	metrics := NewPromMetrics(0.1, 1)
+	metrics.Observe(RequestMetrics{Method: "GET", Host: "a", Route: `/x"y`, StatusCode: 200,
+		Duration: 50e6, BytesIn: 10, BytesOut: 2})
+	metrics.Observe(RequestMetrics{Method: "GET", Host: "a", Route: `/x"y`, StatusCode: 503,
+		Duration: 500e6, ErrorClass: ErrorServer})
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	got := recorder.Body.String()
+
+	labels := `method="GET",host="a",route="/x\"y"`
+	for _, want := range []string{
+		"# TYPE httpx_requests_total counter\n",
+		`httpx_requests_total{` + labels + `,status="200",error=""} 1` + "\n",
+		`httpx_requests_total{` + labels + `,status="503",error="server"} 1` + "\n",
+		"# TYPE httpx_request_duration_seconds histogram\n",
+		`httpx_request_duration_seconds_bucket{` + labels + `,le="0.1"} 1` + "\n",
+		`httpx_request_duration_seconds_bucket{` + labels + `,le="1"} 2` + "\n",
+		`httpx_request_duration_seconds_bucket{` + labels + `,le="+Inf"} 2` + "\n",
+		`httpx_request_duration_seconds_sum{` + labels + `} 0.55` + "\n",
+		`httpx_request_duration_seconds_count{` + labels + `} 2` + "\n",
+		`httpx_request_bytes_total{` + labels + `} 2` + "\n",
+		`httpx_response_bytes_total{` + labels + `} 10` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteTo() missing %q in\n%s", want, got)
+		}
+	}
+}
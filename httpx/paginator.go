@@ -0,0 +1,430 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageInfo describes the page just fetched
+// it is passed to PageStrategy to build the request of the next page
+type PageInfo struct {
+	// Index is the zero based index of the page
+	Index int
+	// Count is the number of items on the page
+	Count int
+	// Total is the number of items fetched so far including the page
+	Total int
+	// Cursor is the cursor decoded from the body of the page
+	Cursor string
+	// URL is the url the page was fetched from
+	URL *url.URL
+	// Header is the response header of the page
+	Header http.Header
+}
+
+// PageStrategy decides how the pages of a list endpoint are requested
+type PageStrategy interface {
+	// First returns the options of the first page
+	First() []ReqOption
+	// Next returns the url and the options of the page after the given page
+	// empty url means the url given to the Paginator, ok is false if there is no more page
+	Next(page PageInfo) (url string, options []ReqOption, ok bool)
+}
+
+// PagePaging requests pages with page number and page size query params
+type PagePaging struct {
+	PageParam  string
+	LimitParam string
+	FirstPage  int
+	// Limit is the page size, a page with less items is the last one
+	// zero stops only on an empty page
+	Limit int
+}
+
+// NewPagePaging creates page paging with page and limit query params starting from page 1
+func NewPagePaging(limit int) *PagePaging {
+	return &PagePaging{
+		PageParam:  "page",
+		LimitParam: "limit",
+		FirstPage:  1,
+		Limit:      limit,
+	}
+}
+
+func (s *PagePaging) options(page int) []ReqOption {
+	options := []ReqOption{WithQuery(s.PageParam, strconv.Itoa(page))}
+	if len(s.LimitParam) != 0 && s.Limit > 0 {
+		options = append(options, WithQuery(s.LimitParam, strconv.Itoa(s.Limit)))
+	}
+	return options
+}
+
+func (s *PagePaging) First() []ReqOption {
+	return s.options(s.FirstPage)
+}
+
+func (s *PagePaging) Next(page PageInfo) (string, []ReqOption, bool) {
+	if isLastPage(page, s.Limit) {
+		return "", nil, false
+	}
+	return "", s.options(s.FirstPage + page.Index + 1), true
+}
+
+// OffsetPaging requests pages with offset and page size query params
+type OffsetPaging struct {
+	OffsetParam string
+	LimitParam  string
+	// Limit is the page size, a page with less items is the last one
+	// zero stops only on an empty page
+	Limit int
+}
+
+// NewOffsetPaging creates offset paging with offset and limit query params
+func NewOffsetPaging(limit int) *OffsetPaging {
+	return &OffsetPaging{
+		OffsetParam: "offset",
+		LimitParam:  "limit",
+		Limit:       limit,
+	}
+}
+
+func (s *OffsetPaging) options(offset int) []ReqOption {
+	options := []ReqOption{WithQuery(s.OffsetParam, strconv.Itoa(offset))}
+	if len(s.LimitParam) != 0 && s.Limit > 0 {
+		options = append(options, WithQuery(s.LimitParam, strconv.Itoa(s.Limit)))
+	}
+	return options
+}
+
+func (s *OffsetPaging) First() []ReqOption {
+	return s.options(0)
+}
+
+func (s *OffsetPaging) Next(page PageInfo) (string, []ReqOption, bool) {
+	if isLastPage(page, s.Limit) {
+		return "", nil, false
+	}
+	return "", s.options(page.Total), true
+}
+
+func isLastPage(page PageInfo, limit int) bool {
+	return page.Count == 0 || (limit > 0 && page.Count < limit)
+}
+
+// CursorPaging passes the cursor decoded from the body as query param
+// the last page has no cursor, see [CursorPageDecoder]
+type CursorPaging struct {
+	CursorParam string
+}
+
+// NewCursorPaging creates cursor paging with the given query param
+func NewCursorPaging(cursorParam string) *CursorPaging {
+	return &CursorPaging{
+		CursorParam: cursorParam,
+	}
+}
+
+func (s *CursorPaging) First() []ReqOption {
+	return nil
+}
+
+func (s *CursorPaging) Next(page PageInfo) (string, []ReqOption, bool) {
+	if len(page.Cursor) == 0 {
+		return "", nil, false
+	}
+	return "", []ReqOption{WithQuery(s.CursorParam, page.Cursor)}, true
+}
+
+// LinkPaging follows RFC 5988 Link header with rel="next"
+type LinkPaging struct{}
+
+// NewLinkPaging creates Link header paging
+func NewLinkPaging() *LinkPaging {
+	return &LinkPaging{}
+}
+
+func (s *LinkPaging) First() []ReqOption {
+	return nil
+}
+
+func (s *LinkPaging) Next(page PageInfo) (string, []ReqOption, bool) {
+	next, ok := ParseLinkHeader(page.Header.Values("Link"))["next"]
+	if !ok {
+		return "", nil, false
+	}
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return "", nil, false
+	}
+	if page.URL != nil {
+		nextURL = page.URL.ResolveReference(nextURL)
+	}
+	// the next link is complete, drop path and queries given to the Paginator
+	return nextURL.String(), []ReqOption{withoutPathAndQueries()}, true
+}
+
+func withoutPathAndQueries() ReqOption {
+	return func(req *Request) error {
+		req.path = ""
+		req.queries = nil
+		return nil
+	}
+}
+
+// ParseLinkHeader parses RFC 5988 Link header values into urls keyed by rel
+func ParseLinkHeader(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, value := range values {
+		for _, link := range splitLinks(value) {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+			for _, param := range parts[1:] {
+				key, val, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				// rel can have multiple space separated relation types
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					rel = strings.ToLower(rel)
+					if _, ok := links[rel]; !ok {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
+
+// splitLinks splits links on commas outside of <>
+func splitLinks(value string) []string {
+	var links []string
+	inURL := false
+	start := 0
+	for i, ch := range value {
+		switch ch {
+		case '<':
+			inURL = true
+		case '>':
+			inURL = false
+		case ',':
+			if !inURL {
+				links = append(links, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(links, value[start:])
+}
+
+// PageDecoder decodes items and the next cursor from a page
+type PageDecoder[T any] func(res *Response) (items []T, cursor string, err error)
+
+// ArrayPageDecoder decodes a page body which is an array of items
+func ArrayPageDecoder[T any](res *Response) (items []T, cursor string, err error) {
+	err = res.Unmarshal(&items)
+	return
+}
+
+// CursorPageDecoder decodes a json object page with items and cursor fields
+// fields can be dot separated path like "meta.next_cursor"
+// null, missing or empty cursor means the last page
+func CursorPageDecoder[T any](itemsField string, cursorField string) PageDecoder[T] {
+	return func(res *Response) (items []T, cursor string, err error) {
+		var body map[string]json.RawMessage
+		if err = res.Unmarshal(&body); err != nil {
+			return
+		}
+
+		if raw, ok := lookupField(body, itemsField); ok {
+			if err = json.Unmarshal(raw, &items); err != nil {
+				return nil, "", fmt.Errorf("failed to decode items: %s", err)
+			}
+		}
+
+		if raw, ok := lookupField(body, cursorField); ok {
+			var value any
+			if err = json.Unmarshal(raw, &value); err != nil {
+				return nil, "", fmt.Errorf("failed to decode cursor: %s", err)
+			}
+			switch v := value.(type) {
+			case nil:
+			case string:
+				cursor = v
+			default:
+				cursor = strings.TrimSpace(string(raw))
+			}
+		}
+		return
+	}
+}
+
+func lookupField(body map[string]json.RawMessage, field string) (json.RawMessage, bool) {
+	names := strings.Split(field, ".")
+	for i, name := range names {
+		raw, ok := body[name]
+		if !ok {
+			return nil, false
+		}
+		if i == len(names)-1 {
+			return raw, true
+		}
+		body = nil
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// Paginator iterates items of a list endpoint page by page
+// pages are requested lazily when the items of the previous page are consumed
+//
+//	p := NewPaginator[Post](ctx, client, url, NewPagePaging(10), WithPath("/posts"))
+//	for p.Next() {
+//		post := p.Item()
+//	}
+//	if err := p.Err(); err != nil {
+//	}
+type Paginator[T any] struct {
+	ctx      context.Context
+	client   *Client
+	url      string
+	options  []ReqOption
+	strategy PageStrategy
+	decoder  PageDecoder[T]
+
+	nextURL     string
+	nextOptions []ReqOption
+	page        PageInfo
+	fetched     int
+	items       []T
+	item        T
+	done        bool
+	err         error
+}
+
+// NewPaginator creates a paginator of GET requests to the url with options
+// the decoder is ArrayPageDecoder by default
+func NewPaginator[T any](ctx context.Context, client *Client, url string, strategy PageStrategy, options ...ReqOption) *Paginator[T] {
+	return &Paginator[T]{
+		ctx:         ctx,
+		client:      client,
+		url:         url,
+		options:     options,
+		strategy:    strategy,
+		decoder:     ArrayPageDecoder[T],
+		nextURL:     url,
+		nextOptions: strategy.First(),
+	}
+}
+
+// SetDecoder sets the decoder of pages
+func (p *Paginator[T]) SetDecoder(decoder PageDecoder[T]) {
+	p.decoder = decoder
+}
+
+// Next advances to the next item, fetching the next page if needed
+// it returns false when there is no more item, the context is done or an error occurs
+func (p *Paginator[T]) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	for {
+		if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+		if len(p.items) != 0 {
+			p.item = p.items[0]
+			p.items = p.items[1:]
+			return true
+		}
+		if p.done {
+			return false
+		}
+		if p.err = p.fetch(); p.err != nil {
+			return false
+		}
+	}
+}
+
+// Item returns the current item
+func (p *Paginator[T]) Item() T {
+	return p.item
+}
+
+// Page returns the info of the last fetched page
+func (p *Paginator[T]) Page() PageInfo {
+	return p.page
+}
+
+// Err returns the error stopped the iteration, nil if all the items are consumed
+func (p *Paginator[T]) Err() error {
+	return p.err
+}
+
+// All consumes all the remaining items
+func (p *Paginator[T]) All() ([]T, error) {
+	var items []T
+	for p.Next() {
+		items = append(items, p.Item())
+	}
+	return items, p.Err()
+}
+
+func (p *Paginator[T]) fetch() error {
+	options := make([]ReqOption, 0, len(p.options)+len(p.nextOptions)+1)
+	options = append(options, p.options...)
+	options = append(options, p.nextOptions...)
+	options = append(options, WithContext(p.ctx))
+
+	res, err := p.client.Get(p.nextURL, options...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	if err = res.statusError(); err != nil {
+		return err
+	}
+
+	items, cursor, err := p.decoder(res)
+	if err != nil {
+		return err
+	}
+
+	p.page = PageInfo{
+		Index:  p.fetched,
+		Count:  len(items),
+		Total:  p.page.Total + len(items),
+		Cursor: cursor,
+		Header: res.Header(),
+	}
+	if res.res.Request != nil {
+		p.page.URL = res.res.Request.URL
+	}
+	p.fetched++
+	p.items = items
+
+	nextURL, nextOptions, ok := p.strategy.Next(p.page)
+	if !ok {
+		p.done = true
+		return nil
+	}
+	if len(nextURL) == 0 {
+		nextURL = p.url
+	}
+	p.nextURL = nextURL
+	p.nextOptions = nextOptions
+	return nil
+}
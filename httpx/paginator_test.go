@@ -0,0 +1,199 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// startListServer serves items 1..total with every paging style
+func startListServer(total int) *httptest.Server {
+	items := make([]int, total)
+	for i := range items {
+		items[i] = i + 1
+	}
+	window := func(offset, limit int) []int {
+		if offset > total {
+			offset = total
+		}
+		end := offset + limit
+		if end > total {
+			end = total
+		}
+		return items[offset:end]
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(window((page-1)*limit, limit))
+	})
+	mux.HandleFunc("/offset", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(window(offset, limit))
+	})
+	mux.HandleFunc("/cursor", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		page := window(offset, 3)
+		var next *string
+		if offset+len(page) < total {
+			cursor := strconv.Itoa(offset + len(page))
+			next = &cursor
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": page,
+			"meta": map[string]any{"next": next},
+		})
+	})
+	linkHandler := func(path string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			offset, _ := strconv.Atoi(r.URL.Query().Get("from"))
+			page := window(offset, 4)
+			if offset+len(page) < total {
+				w.Header().Set("Link", fmt.Sprintf(`<%s?from=%d>; rel="next", <%s?from=0>; rel="first"`, path, offset+len(page), path))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(page)
+		}
+	}
+	mux.HandleFunc("/link", linkHandler("/link"))
+	mux.HandleFunc("/api/v1/link", linkHandler("/api/v1/link"))
+	return httptest.NewServer(mux)
+}
+
+func TestPaginator(t *testing.T) {
+	server := startListServer(10)
+	defer server.Close()
+
+	want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name      string
+		paginator func() *Paginator[int]
+		wantPages int
+	}{
+		{
+			name: "page",
+			paginator: func() *Paginator[int] {
+				return NewPaginator[int](context.Background(), NewClient(), server.URL, NewPagePaging(3), WithPath("/page"))
+			},
+			wantPages: 4,
+		},
+		{
+			name: "page - exact",
+			paginator: func() *Paginator[int] {
+				return NewPaginator[int](context.Background(), NewClient(), server.URL, NewPagePaging(5), WithPath("/page"))
+			},
+			wantPages: 3,
+		},
+		{
+			name: "offset",
+			paginator: func() *Paginator[int] {
+				return NewPaginator[int](context.Background(), NewClient(), server.URL, NewOffsetPaging(4), WithPath("/offset"))
+			},
+			wantPages: 3,
+		},
+		{
+			name: "cursor",
+			paginator: func() *Paginator[int] {
+				p := NewPaginator[int](context.Background(), NewClient(), server.URL, NewCursorPaging("after"), WithPath("/cursor"))
+				p.SetDecoder(CursorPageDecoder[int]("data", "meta.next"))
+				return p
+			},
+			wantPages: 4,
+		},
+		{
+			name: "link",
+			paginator: func() *Paginator[int] {
+				return NewPaginator[int](context.Background(), NewClient(), server.URL, NewLinkPaging(), WithPath("/link"))
+			},
+			wantPages: 3,
+		},
+		{
+			name: "link - path prefix",
+			paginator: func() *Paginator[int] {
+				client := NewClient(WithBaseURL(server.URL), WithPathPrefix("/api/v1"))
+				return NewPaginator[int](context.Background(), client, "/link", NewLinkPaging())
+			},
+			wantPages: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.paginator()
+			got, err := p.All()
+			if err != nil {
+				t.Fatalf("All() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("All() got = %v, want %v", got, want)
+			}
+			if p.Page().Index+1 != tt.wantPages {
+				t.Errorf("All() pages got = %d, want %d", p.Page().Index+1, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestPaginator_Cancel(t *testing.T) {
+	server := startListServer(10)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := NewPaginator[int](ctx, NewClient(), server.URL, NewPagePaging(3), WithPath("/page"))
+
+	var got []int
+	for p.Next() {
+		got = append(got, p.Item())
+		if len(got) == 2 {
+			cancel()
+		}
+	}
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("Next() got = %v", got)
+	}
+	if p.Err() != context.Canceled {
+		t.Errorf("Err() got = %v, want %v", p.Err(), context.Canceled)
+	}
+}
+
+func TestPaginator_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	p := NewPaginator[int](context.Background(), NewClient(), server.URL, NewPagePaging(3))
+	if p.Next() {
+		t.Errorf("Next() want false")
+	}
+	if p.Err() == nil {
+		t.Errorf("Err() want error")
+	}
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	got := ParseLinkHeader([]string{
+		`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=5>; rel="last"`,
+		`<https://api.example.com/items?page=1>; rel="first prev"`,
+	})
+	want := map[string]string{
+		"next":  "https://api.example.com/items?page=2",
+		"last":  "https://api.example.com/items?page=5",
+		"first": "https://api.example.com/items?page=1",
+		"prev":  "https://api.example.com/items?page=1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLinkHeader() got = %v, want %v", got, want)
+	}
+}
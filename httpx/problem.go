@@ -0,0 +1,154 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is a RFC 7807 problem details document
+// it is returned as error from [Response.Unmarshal] for non-2xx responses
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions holds members other than the standard ones
+	Extensions map[string]any
+}
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+func (p *Problem) Error() string {
+	msg := fmt.Sprintf("%d %s", p.Status, p.Title)
+	if len(p.Detail) != 0 {
+		msg += ": " + p.Detail
+	}
+	return msg
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	var standard struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+	}
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return err
+	}
+	p.Type = standard.Type
+	p.Title = standard.Title
+	p.Status = standard.Status
+	p.Detail = standard.Detail
+	p.Instance = standard.Instance
+
+	for _, key := range problemMembers {
+		delete(members, key)
+	}
+	p.Extensions = nil
+	for key, raw := range members {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[key] = value
+	}
+	return nil
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	if len(p.Type) != 0 {
+		members["type"] = p.Type
+	}
+	if len(p.Title) != 0 {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if len(p.Detail) != 0 {
+		members["detail"] = p.Detail
+	}
+	if len(p.Instance) != 0 {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// isProblem reports whether the response is a non-2xx response with a problem document
+func (c *Response) isProblem() bool {
+	if c.res == nil || (c.res.StatusCode >= 200 && c.res.StatusCode < 300) {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(c.res.Header.Get("Content-Type"))
+	return err == nil && mediaType == ProblemContentType
+}
+
+// Problem decodes the problem document and close the body stream
+// it returns nil if the response is not a problem
+// the body is consumed by the first call, the later calls return the same problem
+// the problem has only the status if the body was read before
+func (c *Response) Problem() *Problem {
+	if !c.isProblem() {
+		return nil
+	}
+	if c.problem == nil {
+		c.problem = c.readProblem()
+	}
+	return c.problem
+}
+
+func (c *Response) readProblem() *Problem {
+	defer c.Close()
+
+	// about:blank is the default type
+	problem := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(c.res.StatusCode),
+		Status: c.res.StatusCode,
+	}
+	if c.bufBody == nil {
+		return problem
+	}
+	data, err := io.ReadAll(c.bufBody)
+	if err != nil {
+		return problem
+	}
+
+	var decoded Problem
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return problem
+	}
+	if len(decoded.Type) != 0 {
+		problem.Type = decoded.Type
+	}
+	if len(decoded.Title) != 0 {
+		problem.Title = decoded.Title
+	}
+	if decoded.Status != 0 {
+		problem.Status = decoded.Status
+	}
+	problem.Detail = decoded.Detail
+	problem.Instance = decoded.Instance
+	problem.Extensions = decoded.Extensions
+	return problem
+}
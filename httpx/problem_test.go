@@ -0,0 +1,113 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestResponse_Problem(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantProblem *Problem
+	}{
+		{
+			name:        "problem with extensions",
+			status:      http.StatusForbidden,
+			contentType: "application/problem+json; charset=utf-8",
+			body: `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.",
+"status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc",
+"balance":30}`,
+			wantProblem: &Problem{
+				Type:       "https://example.com/probs/out-of-credit",
+				Title:      "You do not have enough credit.",
+				Status:     403,
+				Detail:     "Your current balance is 30, but that costs 50.",
+				Instance:   "/account/12345/msgs/abc",
+				Extensions: map[string]any{"balance": float64(30)},
+			},
+		},
+		{
+			name:        "problem with defaults",
+			status:      http.StatusNotFound,
+			contentType: "application/problem+json",
+			body:        `{}`,
+			wantProblem: &Problem{
+				Type:   "about:blank",
+				Title:  "Not Found",
+				Status: 404,
+			},
+		},
+		{
+			name:        "not a problem",
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"title":"bad"}`,
+			wantProblem: nil,
+		},
+		{
+			name:        "problem on 2xx",
+			status:      http.StatusOK,
+			contentType: "application/problem+json",
+			body:        `{"title":"ok"}`,
+			wantProblem: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			res, err := NewClient().Get(server.URL)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			defer res.Close()
+
+			gotProblem := res.Problem()
+			if !reflect.DeepEqual(gotProblem, tt.wantProblem) {
+				t.Errorf("Problem() got = %#v, want %#v", gotProblem, tt.wantProblem)
+			}
+			if again := res.Problem(); again != gotProblem {
+				t.Errorf("Problem() again got = %#v, want %#v", again, gotProblem)
+			}
+		})
+	}
+}
+
+func TestResponse_UnmarshalProblem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"title":"conflict","detail":"already exists"}`))
+	}))
+	defer server.Close()
+
+	res, err := NewClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	var body map[string]any
+	err = res.Unmarshal(&body)
+
+	var problem *Problem
+	if !errors.As(err, &problem) {
+		t.Fatalf("Unmarshal() want *Problem, got %v", err)
+	}
+	if problem.Status != http.StatusConflict || problem.Detail != "already exists" {
+		t.Errorf("Unmarshal() got problem %#v", problem)
+	}
+	if problem.Error() != "409 conflict: already exists" {
+		t.Errorf("Error() got %v", problem.Error())
+	}
+}
@@ -0,0 +1,122 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ProgressFunc reports the number of bytes transferred so far
+// total is -1 if unknown
+type ProgressFunc func(transferred, total int64)
+
+// WithUploadProgress sets the callback reporting the progress of sending the request body
+func WithUploadProgress(progress ProgressFunc) ReqOption {
+	return func(req *Request) error {
+		req.uploadProgress = progress
+		return nil
+	}
+}
+
+// WithUploadLimit limits the bandwidth of sending the request body in bytes per second
+func WithUploadLimit(bytesPerSecond int64) ReqOption {
+	return func(req *Request) error {
+		req.uploadLimit = bytesPerSecond
+		return nil
+	}
+}
+
+// WithResponseProgress sets the callback reporting the progress of reading the response body
+func WithResponseProgress(progress ProgressFunc) ReqOption {
+	return func(req *Request) error {
+		req.responseProgress = progress
+		return nil
+	}
+}
+
+// WithResponseLimit limits the bandwidth of reading the response body in bytes per second
+func WithResponseLimit(bytesPerSecond int64) ReqOption {
+	return func(req *Request) error {
+		req.responseLimit = bytesPerSecond
+		return nil
+	}
+}
+
+type progressWriter struct {
+	wr          io.Writer
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+}
+
+func (w *progressWriter) Write(p []byte) (n int, err error) {
+	n, err = w.wr.Write(p)
+	w.transferred += int64(n)
+	w.progress(w.transferred, w.total)
+	return
+}
+
+// progressReader reports progress and throttles reads to limit bytes per second
+type progressReader struct {
+	ctx         context.Context
+	rd          io.Reader
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+	limit       int64
+	start       time.Time
+}
+
+func newProgressReader(ctx context.Context, rd io.Reader, total int64, progress ProgressFunc, limit int64) *progressReader {
+	return &progressReader{
+		ctx:      ctx,
+		rd:       rd,
+		total:    total,
+		progress: progress,
+		limit:    limit,
+	}
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	if r.limit > 0 {
+		// read a tenth of a second worth at most to keep the rate smooth
+		if chunk := r.limit/10 + 1; int64(len(p)) > chunk {
+			p = p[:chunk]
+		}
+		if r.start.IsZero() {
+			r.start = time.Now()
+		}
+	}
+
+	n, err = r.rd.Read(p)
+	if n <= 0 {
+		return
+	}
+	r.transferred += int64(n)
+	if r.progress != nil {
+		r.progress(r.transferred, r.total)
+	}
+
+	if r.limit > 0 {
+		expected := time.Duration(float64(r.transferred) / float64(r.limit) * float64(time.Second))
+		if wait := expected - time.Since(r.start); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-r.ctx.Done():
+				return n, r.ctx.Err()
+			}
+		}
+	}
+	return
+}
+
+type progressReadCloser struct {
+	*progressReader
+	closer io.Closer
+}
+
+func (r *progressReadCloser) Close() error {
+	return r.closer.Close()
+}
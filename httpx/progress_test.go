@@ -0,0 +1,100 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadProgress(t *testing.T) {
+	serverUrl := startServer()
+	defer stopServer()
+
+	body := strings.Repeat("x", 8*1024)
+	tests := []struct {
+		name        string
+		options     []ReqOption
+		minDuration time.Duration
+	}{
+		{
+			name: "WithBytes",
+			options: []ReqOption{
+				WithBytes("text/plain", []byte(body)),
+			},
+		},
+		{
+			name: "WithMultipartReader",
+			options: []ReqOption{
+				WithMultipartReader("field", "file.txt", bytes.NewBufferString(body)),
+			},
+		},
+		{
+			name: "WithBytes - limit",
+			options: []ReqOption{
+				WithBytes("text/plain", []byte(body)),
+				WithUploadLimit(32 * 1024),
+			},
+			minDuration: 200 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lastSent, lastTotal int64
+			options := append(tt.options, WithUploadProgress(func(sent, total int64) {
+				lastSent, lastTotal = sent, total
+			}))
+
+			start := time.Now()
+			res, err := NewClient().Post(serverUrl, options...)
+			if err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+			got, _ := io.ReadAll(res.BufferedReader())
+			res.Close()
+
+			if elapsed := time.Since(start); elapsed < tt.minDuration {
+				t.Errorf("Post() took %v, want at least %v", elapsed, tt.minDuration)
+			}
+			if lastTotal <= 0 || lastSent != lastTotal || lastTotal != int64(len(got)) {
+				t.Errorf("Post() progress got = %d/%d, want %d", lastSent, lastTotal, len(got))
+			}
+		})
+	}
+}
+
+func TestResponseProgress(t *testing.T) {
+	serverUrl := startServer()
+	defer stopServer()
+
+	body := strings.Repeat("x", 8*1024)
+	var lastRead, lastTotal int64
+	start := time.Now()
+	res, err := NewClient().Post(serverUrl,
+		WithString("text/plain", body),
+		WithResponseProgress(func(read, total int64) {
+			lastRead, lastTotal = read, total
+		}),
+		WithResponseLimit(32*1024),
+		WithBodyParser("text/plain", TextBodyParser),
+	)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	var got string
+	if err = res.Unmarshal(&got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if got != body {
+		t.Errorf("Unmarshal() got %d bytes, want %d", len(got), len(body))
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Post() took %v, want at least 200ms", elapsed)
+	}
+	// the echo server responds chunked, total is unknown
+	if lastRead != int64(len(body)) || lastTotal != -1 {
+		t.Errorf("Post() progress got = %d/%d, want %d", lastRead, lastTotal, len(body))
+	}
+}
@@ -0,0 +1,105 @@
+// Package protobuf provides protocol buffers body parsers and request encoders for httpx.
+// It lives in its own package so that httpx itself does not depend on protobuf.
+package protobuf
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rookiecj/go-langext/httpx"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// ContentType is the content type for binary encoded messages
+	ContentType = "application/x-protobuf"
+	// AltContentType is the alternative content type for binary encoded messages
+	AltContentType = "application/protobuf"
+	// JSONContentType is the content type for protojson encoded messages
+	JSONContentType = "application/json; charset=UTF-8"
+)
+
+// BodyParsers holds the binary parser for both protobuf content types
+var BodyParsers = map[string]httpx.BodyParser{
+	ContentType:    BodyParser,
+	AltContentType: BodyParser,
+}
+
+// WithDefaultBodyParsers registers BodyParsers on a client
+func WithDefaultBodyParsers() httpx.ClientOption {
+	return httpx.WithDefaultBodyParsers(BodyParsers)
+}
+
+func toMessage(obj any) (proto.Message, error) {
+	message, ok := obj.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", obj)
+	}
+	return message, nil
+}
+
+// BodyParser parses application/x-protobuf and application/protobuf content type
+// bodyPtr should be a proto.Message
+func BodyParser(buf io.Reader, bodyPtr any) (err error) {
+	message, err := toMessage(bodyPtr)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(buf)
+	if err != nil {
+		return fmt.Errorf("error while reading: %s", err)
+	}
+	if err = proto.Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to unmarshal: %s", err)
+	}
+	return nil
+}
+
+// JSONBodyParser parses protojson encoded body
+// it is not registered by default because it shares application/json with httpx.JsonBodyParser,
+// use httpx.WithBodyParser to parse a response with it
+func JSONBodyParser(buf io.Reader, bodyPtr any) (err error) {
+	message, err := toMessage(bodyPtr)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(buf)
+	if err != nil {
+		return fmt.Errorf("error while reading: %s", err)
+	}
+	if err = protojson.Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to unmarshal: %s", err)
+	}
+	return nil
+}
+
+// Marshal is a httpx.Marshaller encoding a proto.Message in binary format
+func Marshal(obj any) ([]byte, error) {
+	message, err := toMessage(obj)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(message)
+}
+
+// MarshalJSON is a httpx.Marshaller encoding a proto.Message with protojson
+func MarshalJSON(obj any) ([]byte, error) {
+	message, err := toMessage(obj)
+	if err != nil {
+		return nil, err
+	}
+	return protojson.Marshal(message)
+}
+
+// WithMessage sets the binary encoded message as request body
+func WithMessage(message proto.Message) httpx.ReqOption {
+	return httpx.WithMarshalObject(ContentType, message, Marshal)
+}
+
+// WithJSONMessage sets the protojson encoded message as request body
+func WithJSONMessage(message proto.Message) httpx.ReqOption {
+	return httpx.WithMarshalObject(JSONContentType, message, MarshalJSON)
+}
@@ -0,0 +1,101 @@
+package protobuf
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rookiecj/go-langext/httpx"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func startEchoServer(contentType string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqBytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if contentType == "" {
+			contentType = r.Header.Get("Content-Type")
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(reqBytes)
+	}))
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		parser      httpx.BodyParser
+		option      func(message proto.Message) httpx.ReqOption
+	}{
+		{
+			name:        "binary - x-protobuf",
+			contentType: ContentType,
+			option:      WithMessage,
+		},
+		{
+			name:        "binary - protobuf",
+			contentType: AltContentType,
+			option:      WithMessage,
+		},
+		{
+			name:        "protojson",
+			contentType: "application/json",
+			parser:      JSONBodyParser,
+			option:      WithJSONMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startEchoServer(tt.contentType)
+			defer server.Close()
+
+			client := httpx.NewClient(WithDefaultBodyParsers())
+			options := []httpx.ReqOption{tt.option(wrapperspb.String("hello"))}
+			if tt.parser != nil {
+				options = append(options, httpx.WithBodyParser(tt.contentType, tt.parser))
+			}
+			res, err := client.Post(server.URL, options...)
+			if err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+
+			got := &wrapperspb.StringValue{}
+			if err = res.Unmarshal(got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got.GetValue() != "hello" {
+				t.Errorf("Unmarshal() got = %v, want hello", got.GetValue())
+			}
+		})
+	}
+}
+
+func TestBodyParser_NotMessage(t *testing.T) {
+	data, _ := proto.Marshal(wrapperspb.String("hello"))
+
+	var notMessage string
+	if err := BodyParser(bytes.NewReader(data), &notMessage); err == nil {
+		t.Errorf("BodyParser() want error for non proto.Message")
+	}
+	if err := JSONBodyParser(bytes.NewReader(data), &notMessage); err == nil {
+		t.Errorf("JSONBodyParser() want error for non proto.Message")
+	}
+}
+
+func TestWithMessage_NotMessage(t *testing.T) {
+	server := startEchoServer("")
+	defer server.Close()
+
+	_, err := httpx.NewClient().Post(server.URL, httpx.WithMarshalObject(ContentType, "not a message", Marshal))
+	if err == nil {
+		t.Errorf("Post() want error for non proto.Message")
+	}
+}
@@ -2,10 +2,12 @@ package httpx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
@@ -14,12 +16,33 @@ import (
 type Request struct {
 	// fields can be hidden
 	// becuase the request only be created and accessed in the client
-	headers map[string][]string
-	path    string
-	queries map[string][]string
+	ctx            context.Context
+	headers        map[string][]string
+	removedHeaders map[string]struct{}
+	path           string
+	queries        map[string][]string
 
-	contentType string
-	body        *bytes.Buffer
+	// route is reported to MetricsCollector, pathTemplate of WithPathParams if empty
+	route        string
+	pathTemplate string
+
+	contentType     string
+	contentEncoding string
+	body            *bytes.Buffer
+
+	uploadProgress   ProgressFunc
+	uploadLimit      int64
+	responseProgress ProgressFunc
+	responseLimit    int64
+
+	// 0 is the client default, negative is no limit
+	maxResponseSize int64
+
+	signer    Signer
+	signerSet bool
+
+	// idempotent allows retries of any method while the Idempotency-Key header is set
+	idempotent bool
 
 	// response body parser
 	bodyParser map[string]BodyParser
@@ -29,8 +52,21 @@ type ReqOption func(req *Request) error
 
 func newRequest() *Request {
 	return &Request{
-		headers: make(map[string][]string),
-		body:    nil,
+		ctx:        context.Background(),
+		headers:    make(map[string][]string),
+		body:       nil,
+		bodyParser: make(map[string]BodyParser),
+	}
+}
+
+// WithContext sets the context of the request
+func WithContext(ctx context.Context) ReqOption {
+	return func(req *Request) error {
+		if ctx == nil {
+			return fmt.Errorf("nil context")
+		}
+		req.ctx = ctx
+		return nil
 	}
 }
 
@@ -39,6 +75,7 @@ func WithHeader(key, value string) ReqOption {
 		if req.headers == nil {
 			req.headers = make(map[string][]string)
 		}
+		key = http.CanonicalHeaderKey(key)
 		req.headers[key] = append(req.headers[key], value)
 		return nil
 	}
@@ -50,12 +87,26 @@ func WithHeaders(headers map[string]string) ReqOption {
 			req.headers = make(map[string][]string)
 		}
 		for k, v := range headers {
+			k = http.CanonicalHeaderKey(k)
 			req.headers[k] = append(req.headers[k], v)
 		}
 		return nil
 	}
 }
 
+// WithoutHeader removes the default header and the header added by previous options
+func WithoutHeader(key string) ReqOption {
+	return func(req *Request) error {
+		key = http.CanonicalHeaderKey(key)
+		delete(req.headers, key)
+		if req.removedHeaders == nil {
+			req.removedHeaders = make(map[string]struct{})
+		}
+		req.removedHeaders[key] = struct{}{}
+		return nil
+	}
+}
+
 func WithPath(path string) ReqOption {
 	return func(req *Request) error {
 		req.path += path
@@ -63,6 +114,26 @@ func WithPath(path string) ReqOption {
 	}
 }
 
+// WithPathParams appends the path template with {name} placeholders replaced by escaped params
+// params is a map or a struct with `path:"name"` tags
+//
+//	WithPathParams("/users/{id}/posts/{postId}", map[string]any{"id": 1, "postId": "a/b"})
+func WithPathParams(template string, params any) ReqOption {
+	return func(req *Request) error {
+		values, err := pathParams(params)
+		if err != nil {
+			return err
+		}
+		path, err := expandPath(template, values)
+		if err != nil {
+			return err
+		}
+		req.path += path
+		req.pathTemplate += template
+		return nil
+	}
+}
+
 func WithQuery(key, value string) ReqOption {
 	return func(req *Request) error {
 		if req.queries == nil {
@@ -85,6 +156,26 @@ func WithQueries(queries map[string][]string) ReqOption {
 	}
 }
 
+// WithQueryStruct adds the fields of a struct with `url:"name[,option...]"` tags as query params
+// options are
+//   - omitempty: skip zero value, nil pointer is always skipped
+//   - comma, space: join slice elements instead of repeating the name
+//   - brackets: append [] to the name of slice
+//   - unix, unixmilli: format time.Time as unix time, `layout:"2006-01-02"` tag sets the layout(RFC3339 by default)
+func WithQueryStruct(obj any) ReqOption {
+	return withStructQuery(obj, "url")
+}
+
+func withStructQuery(obj any, tag string) ReqOption {
+	return func(req *Request) error {
+		values, err := structValues(obj, tag)
+		if err != nil {
+			return err
+		}
+		return WithQueries(values)(req)
+	}
+}
+
 func WithBuffer(contentType string, body *bytes.Buffer) ReqOption {
 	return func(req *Request) error {
 		req.contentType = contentType
@@ -118,10 +209,12 @@ func WithJsonObject(obj any) ReqOption {
 }
 
 func WithMarshalObject(contentType string, obj any, marshaller Marshaller) ReqOption {
-	if body, err := marshaller(obj); err != nil {
-		return nil
-	} else {
-		return WithBytes(contentType, body)
+	return func(req *Request) error {
+		body, err := marshaller(obj)
+		if err != nil {
+			return fmt.Errorf("failed to marshal: %s", err)
+		}
+		return WithBytes(contentType, body)(req)
 	}
 }
 
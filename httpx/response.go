@@ -2,6 +2,7 @@ package httpx
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +14,37 @@ type Response struct {
 	res         *http.Response
 	bufBody     *bufio.Reader
 	bodyParsers map[string]BodyParser
+	// cancel releases the context of the request when the body is closed
+	cancel context.CancelFunc
+	// limit is the body limited by the max response size
+	limit *limitReadCloser
+	// problem is decoded from the body by Problem
+	problem *Problem
+}
+
+// StatusError is returned for an unexpected status without problem document
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %s", e.Status)
+}
+
+// statusError returns the problem or StatusError if the status is not 2xx and closes the body
+func (c *Response) statusError() error {
+	if c.res.StatusCode >= 200 && c.res.StatusCode < 300 {
+		return nil
+	}
+	if problem := c.Problem(); problem != nil {
+		return problem
+	}
+	c.Close()
+	return &StatusError{
+		StatusCode: c.res.StatusCode,
+		Status:     c.res.Status,
+	}
 }
 
 func (c *Response) Header() map[string][]string {
@@ -41,6 +73,10 @@ func (c *Response) Close() {
 		c.res.Body.Close()
 		c.res.Body = nil
 	}
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
 }
 
 func (c *Response) getBodyParser(contentType string) BodyParser {
@@ -54,8 +90,13 @@ func (c *Response) getBodyParser(contentType string) BodyParser {
 }
 
 // Unmarshal unmarshal body and close the body stream
+// a problem document on non-2xx response is returned as *Problem error
 func (c *Response) Unmarshal(ptrType any) (err error) {
 
+	if problem := c.Problem(); problem != nil {
+		return problem
+	}
+
 	var contentTypes []string
 	var ok bool
 	if contentTypes, ok = c.Header()["Content-Type"]; !ok {
@@ -71,6 +112,10 @@ func (c *Response) Unmarshal(ptrType any) (err error) {
 
 	// parse body
 	err = bodyParser(c.BufferedReader(), ptrType)
+	// parsers may not keep the error type
+	if err != nil && c.limit != nil && c.limit.err != nil {
+		err = c.limit.err
+	}
 
 	return
 }
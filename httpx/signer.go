@@ -0,0 +1,307 @@
+package httpx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Signer signs a request after all the options are applied
+// it is called for every attempt with the body to be sent, nil if none
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignerFunc is a function Signer
+type SignerFunc func(req *http.Request, body []byte) error
+
+func (f SignerFunc) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// WithDefaultSigner signs every request with the signer
+func WithDefaultSigner(signer Signer) ClientOption {
+	return func(clientOptions *clientOptions) {
+		clientOptions.signer = signer
+	}
+}
+
+// WithSigner signs the request with the signer instead of the client default, nil disables signing
+func WithSigner(signer Signer) ReqOption {
+	return func(req *Request) error {
+		req.signer = signer
+		req.signerSet = true
+		return nil
+	}
+}
+
+// CanonicalRequest is the components of a request HMACSigner signs
+type CanonicalRequest struct {
+	Method string
+	// Path is the escaped path, / if empty
+	Path string
+	// Query is the escaped query sorted by key and then by the values of each key
+	Query string
+	// Headers is the lowercase name:value lines of the signed headers in order
+	Headers string
+	// SignedHeaders is the lowercase names of the signed headers joined with ;
+	SignedHeaders string
+	// BodyHash is the hex encoded hash of the body
+	BodyHash  string
+	Timestamp string
+}
+
+// String joins the components with newlines in the order of the fields
+func (c CanonicalRequest) String() string {
+	return strings.Join([]string{c.Method, c.Path, c.Query, c.Headers, c.SignedHeaders, c.BodyHash, c.Timestamp}, "\n")
+}
+
+// HMACSigner signs the canonical string of a request with a shared secret
+//
+//	Authorization: HMAC-SHA256 KeyId=<KeyID>, SignedHeaders=<names>, Signature=<hex>
+type HMACSigner struct {
+	KeyID  string
+	Secret []byte
+	// Hash is sha256.New if nil
+	Hash func() hash.Hash
+	// Headers are the names of the headers to sign, host is the host of the url
+	Headers []string
+	// TimestampHeader has the time of signing in RFC 3339, X-Timestamp if empty
+	TimestampHeader string
+	// Canonicalize builds the string to sign, CanonicalRequest.String if nil
+	Canonicalize func(c CanonicalRequest) string
+	// Authorize sets the signature on the request, the Authorization header above if nil
+	Authorize func(req *http.Request, keyID, signedHeaders, signature string)
+	// Now is time.Now if nil
+	Now func() time.Time
+}
+
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	newHash := s.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestampHeader := s.TimestampHeader
+	if len(timestampHeader) == 0 {
+		timestampHeader = "X-Timestamp"
+	}
+	timestamp := now().UTC().Format(time.RFC3339)
+	req.Header.Set(timestampHeader, timestamp)
+
+	bodyHash := newHash()
+	bodyHash.Write(body)
+	headers, signedHeaders := canonicalHeaders(req, s.Headers)
+	path := req.URL.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+	c := CanonicalRequest{
+		Method:        req.Method,
+		Path:          path,
+		Query:         canonicalQuery(req.URL.Query()),
+		Headers:       headers,
+		SignedHeaders: signedHeaders,
+		BodyHash:      hex.EncodeToString(bodyHash.Sum(nil)),
+		Timestamp:     timestamp,
+	}
+	canonical := c.String()
+	if s.Canonicalize != nil {
+		canonical = s.Canonicalize(c)
+	}
+
+	mac := hmac.New(newHash, s.Secret)
+	mac.Write([]byte(canonical))
+	signature := hex.EncodeToString(mac.Sum(nil))
+	if s.Authorize != nil {
+		s.Authorize(req, s.KeyID, signedHeaders, signature)
+	} else {
+		req.Header.Set("Authorization", fmt.Sprintf("HMAC-SHA256 KeyId=%s, SignedHeaders=%s, Signature=%s",
+			s.KeyID, signedHeaders, signature))
+	}
+	return nil
+}
+
+// canonicalHeaders returns name:value lines and names of the headers in the order of names
+// values are trimmed and joined with comma
+func canonicalHeaders(req *http.Request, names []string) (headers string, signedHeaders string) {
+	var lines, lowerNames []string
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		var value string
+		if lower == "host" {
+			value = requestHost(req)
+		} else {
+			values := req.Header.Values(name)
+			trimmed := make([]string, len(values))
+			for i, v := range values {
+				trimmed[i] = strings.Join(strings.Fields(v), " ")
+			}
+			value = strings.Join(trimmed, ",")
+		}
+		lines = append(lines, lower+":"+value)
+		lowerNames = append(lowerNames, lower)
+	}
+	return strings.Join(lines, "\n"), strings.Join(lowerNames, ";")
+}
+
+func requestHost(req *http.Request) string {
+	if len(req.Host) != 0 {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+// canonicalQuery encodes the query with RFC 3986 escaping sorted by the escaped key and then by the value
+// the keys are compared as a whole so max sorts before max-items
+func canonicalQuery(query url.Values) string {
+	escaped := make(map[string][]string, len(query))
+	keys := make([]string, 0, len(query))
+	for key, values := range query {
+		k := uriEncode(key, true)
+		keys = append(keys, k)
+		for _, value := range values {
+			escaped[k] = append(escaped[k], uriEncode(value, true))
+		}
+		sort.Strings(escaped[k])
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		for _, v := range escaped[k] {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes all but the unreserved characters of RFC 3986, / is kept unless encodeSlash
+func uriEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+)
+
+// SigV4Signer signs requests with AWS Signature Version 4
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+type SigV4Signer struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is sent in X-Amz-Security-Token of temporary credentials
+	SessionToken string
+	Region       string
+	Service      string
+	// ContentSHA256 sets X-Amz-Content-Sha256 as S3 requires
+	ContentSHA256 bool
+	// DisableDoubleEncoding encodes the path once as S3 requires
+	DisableDoubleEncoding bool
+	// Now is time.Now if nil
+	Now func() time.Time
+}
+
+// sigV4IgnoredHeaders are not signed since proxies may change them
+var sigV4IgnoredHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+	"traceparent":     true,
+	"tracestate":      true,
+}
+
+func (s *SigV4Signer) Sign(req *http.Request, body []byte) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	t := now().UTC()
+	amzDate := t.Format(sigV4TimeFormat)
+	date := t.Format("20060102")
+
+	bodyHash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(bodyHash[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if len(s.SessionToken) != 0 {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	if s.ContentSHA256 {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	names := []string{"host"}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if !sigV4IgnoredHeaders[lower] && lower != "host" {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	headers, signedHeaders := canonicalHeaders(req, names)
+
+	path := req.URL.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+	path = uriEncode(path, false)
+	if !s.DisableDoubleEncoding {
+		path = uriEncode(path, false)
+	}
+
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		headers + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+
+	scope := strings.Join([]string{date, s.Region, s.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,138 @@
+package httpx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// aws-sig-v4-test-suite
+func TestSigV4Signer(t *testing.T) {
+	signer := &SigV4Signer{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+		Now: func() time.Time {
+			return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+		},
+	}
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "get-vanilla",
+			url:  "http://example.amazonaws.com/",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "get-vanilla-query-order-key-case",
+			url:  "http://example.amazonaws.com/?Param2=value2&Param1=value1",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name: "get-query-prefix-key",
+			url:  "http://example.amazonaws.com/?max-items=3&max=2",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=27297750ec8763a100b08a03ae73e55f2395f61209a4e99146bc248916955281",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			req.Header.Set("User-Agent", "ignored")
+			if err := signer.Sign(req, nil); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Sign() got = %v\nwant %v", got, tt.want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date got = %v", got)
+			}
+		})
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	secret := []byte("secret")
+	var gotAuth, gotCanonical string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		body := make([]byte, r.ContentLength)
+		r.Body.Read(body)
+		bodyHash := sha256.Sum256(body)
+		gotCanonical = strings.Join([]string{r.Method, r.URL.EscapedPath(), "a=1&b=2&b=3",
+			"host:" + r.Host + "\nx-tenant:t1", "host;x-tenant", hex.EncodeToString(bodyHash[:]),
+			r.Header.Get("X-Timestamp")}, "\n")
+	}))
+	defer server.Close()
+
+	signer := &HMACSigner{
+		KeyID:   "key1",
+		Secret:  secret,
+		Headers: []string{"Host", "X-Tenant"},
+		Now: func() time.Time {
+			return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		},
+	}
+	client := NewClient(WithDefaultSigner(signer), WithDefaultHeader("X-Tenant", "t0"))
+	// the signer sees the headers of the request options
+	res, err := client.Post(server.URL+"/orders?b=3&a=1",
+		WithQuery("b", "2"), WithHeader("X-Tenant", "t1"), WithString("text/plain", "body"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	res.Close()
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(gotCanonical))
+	want := "HMAC-SHA256 KeyId=key1, SignedHeaders=host;x-tenant, Signature=" + hex.EncodeToString(mac.Sum(nil))
+	if gotAuth != want {
+		t.Errorf("Authorization got = %v\nwant %v", gotAuth, want)
+	}
+
+	// disabled by the request
+	res, err = client.Get(server.URL, WithSigner(nil))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	res.Close()
+	if gotAuth != "" {
+		t.Errorf("Authorization got = %v, want none", gotAuth)
+	}
+}
+
+func TestSigner_Retry(t *testing.T) {
+	var signed int
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithRetries(1), WithDefaultSigner(SignerFunc(func(req *http.Request, body []byte) error {
+		signed++
+		return nil
+	})))
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	res.Close()
+	if signed != 2 {
+		t.Errorf("signed got = %d, want 2 for each attempt", signed)
+	}
+}
@@ -0,0 +1,250 @@
+// Package stream turns the body of an httpx.Response into a lazily consumed stream of values.
+// The body is closed when the stream is finished, fails or is closed.
+package stream
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rookiecj/go-langext/httpx"
+)
+
+// Stream is a sequence of values read on demand
+//
+//	s := stream.NDJSON[Event](res)
+//	defer s.Close()
+//	for s.Next() {
+//		event := s.Item()
+//	}
+//	if err := s.Err(); err != nil {
+//	}
+type Stream[T any] struct {
+	// next returns the next value, io.EOF at the end
+	next   func() (T, error)
+	closer func() error
+	item   T
+	err    error
+	done   bool
+}
+
+// New creates a stream of next, closer is called once when the stream is done
+// next returns io.EOF at the end
+func New[T any](next func() (T, error), closer func() error) *Stream[T] {
+	return &Stream[T]{next: next, closer: closer}
+}
+
+// Fail returns a stream failed with err
+func Fail[T any](err error) *Stream[T] {
+	return &Stream[T]{err: err, done: true}
+}
+
+// Next advances to the next value, false at the end or on error
+func (s *Stream[T]) Next() bool {
+	if s.done {
+		return false
+	}
+	item, err := s.next()
+	if err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		s.Close()
+		return false
+	}
+	s.item = item
+	return true
+}
+
+// Item returns the current value
+func (s *Stream[T]) Item() T {
+	return s.item
+}
+
+// Err returns the error stopped the stream, nil at the end
+func (s *Stream[T]) Err() error {
+	return s.err
+}
+
+// Close stops the stream and closes the body
+func (s *Stream[T]) Close() error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	var zero T
+	s.item = zero
+	if s.closer != nil {
+		return s.closer()
+	}
+	return nil
+}
+
+// Map returns a stream of the values converted by fn
+func Map[T any, R any](s *Stream[T], fn func(T) (R, error)) *Stream[R] {
+	return New(func() (R, error) {
+		if !s.Next() {
+			var zero R
+			if err := s.Err(); err != nil {
+				return zero, err
+			}
+			return zero, io.EOF
+		}
+		return fn(s.Item())
+	}, s.Close)
+}
+
+// Filter returns a stream of the values fn returns true
+func Filter[T any](s *Stream[T], fn func(T) bool) *Stream[T] {
+	return New(func() (T, error) {
+		for s.Next() {
+			if fn(s.Item()) {
+				return s.Item(), nil
+			}
+		}
+		var zero T
+		if err := s.Err(); err != nil {
+			return zero, err
+		}
+		return zero, io.EOF
+	}, s.Close)
+}
+
+// Collect reads all the values of the stream
+func Collect[T any](s *Stream[T]) ([]T, error) {
+	defer s.Close()
+	var items []T
+	for s.Next() {
+		items = append(items, s.Item())
+	}
+	return items, s.Err()
+}
+
+// ForEach calls fn with each value until fn returns an error
+func ForEach[T any](s *Stream[T], fn func(T) error) error {
+	defer s.Close()
+	for s.Next() {
+		if err := fn(s.Item()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// body returns the body of a 2xx response
+// otherwise the problem or *httpx.StatusError is returned and the response is closed
+func body(res *httpx.Response) (io.Reader, error) {
+	if res.StatusCode() < 200 || res.StatusCode() >= 300 {
+		if problem := res.Problem(); problem != nil {
+			return nil, problem
+		}
+		res.Close()
+		return nil, &httpx.StatusError{StatusCode: res.StatusCode(), Status: res.Status()}
+	}
+	if reader := res.BufferedReader(); reader != nil {
+		return reader, nil
+	}
+	// 204 No Content
+	return strings.NewReader(""), nil
+}
+
+func closer(res *httpx.Response) func() error {
+	return func() error {
+		res.Close()
+		return nil
+	}
+}
+
+// NDJSON streams newline delimited json values, blank lines are skipped
+func NDJSON[T any](res *httpx.Response) *Stream[T] {
+	reader, err := body(res)
+	if err != nil {
+		return Fail[T](err)
+	}
+	decoder := json.NewDecoder(reader)
+	return New(func() (item T, err error) {
+		if err = decoder.Decode(&item); err != nil && err != io.EOF {
+			err = fmt.Errorf("failed to decode: %s", err)
+		}
+		return
+	}, closer(res))
+}
+
+// JSONArray streams the elements of a json array without reading the whole array
+func JSONArray[T any](res *httpx.Response) *Stream[T] {
+	reader, err := body(res)
+	if err != nil {
+		return Fail[T](err)
+	}
+	decoder := json.NewDecoder(reader)
+	started := false
+	return New(func() (item T, err error) {
+		if !started {
+			started = true
+			token, err := decoder.Token()
+			if err != nil {
+				return item, fmt.Errorf("failed to decode: %s", err)
+			}
+			if delim, ok := token.(json.Delim); !ok || delim != '[' {
+				return item, fmt.Errorf("failed to decode: %v is not an array", token)
+			}
+		}
+		if !decoder.More() {
+			// the array is closed and nothing follows
+			token, err := decoder.Token()
+			if err != nil {
+				return item, fmt.Errorf("failed to decode: %s", err)
+			}
+			if delim, ok := token.(json.Delim); !ok || delim != ']' {
+				return item, fmt.Errorf("failed to decode: unexpected %v", token)
+			}
+			if _, err = decoder.Token(); err != io.EOF {
+				return item, fmt.Errorf("failed to decode: unexpected data after the array")
+			}
+			return item, io.EOF
+		}
+		if err = decoder.Decode(&item); err != nil {
+			err = fmt.Errorf("failed to decode: %s", err)
+		}
+		return
+	}, closer(res))
+}
+
+// CSV streams the records of csv, configure modifies the reader like Comma or FieldsPerRecord
+func CSV(res *httpx.Response, configure ...func(reader *csv.Reader)) *Stream[[]string] {
+	reader, err := body(res)
+	if err != nil {
+		return Fail[[]string](err)
+	}
+	csvReader := csv.NewReader(reader)
+	for _, fn := range configure {
+		fn(csvReader)
+	}
+	return New(func() (record []string, err error) {
+		if record, err = csvReader.Read(); err != nil && err != io.EOF {
+			err = fmt.Errorf("failed to read csv: %s", err)
+		}
+		return
+	}, closer(res))
+}
+
+// Lines streams the lines of text without line endings
+func Lines(res *httpx.Response) *Stream[string] {
+	reader, err := body(res)
+	if err != nil {
+		return Fail[string](err)
+	}
+	scanner := bufio.NewScanner(reader)
+	return New(func() (string, error) {
+		if scanner.Scan() {
+			return scanner.Text(), nil
+		}
+		if err := scanner.Err(); err != nil {
+			return "", fmt.Errorf("failed to read line: %s", err)
+		}
+		return "", io.EOF
+	}, closer(res))
+}
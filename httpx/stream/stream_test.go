@@ -0,0 +1,148 @@
+package stream
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/rookiecj/go-langext/httpx"
+)
+
+type event struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func startBodyServer(status int, contentType, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func get(t *testing.T, url string) *httpx.Response {
+	res, err := httpx.NewClient().Get(url)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return res
+}
+
+func TestNDJSON(t *testing.T) {
+	server := startBodyServer(http.StatusOK, "application/x-ndjson", "{\"id\":1,\"name\":\"a\"}\n\n{\"id\":2,\"name\":\"b\"}\n{\"id\":3,\"name\":\"c\"}\n")
+	defer server.Close()
+
+	s := Filter(NDJSON[event](get(t, server.URL)), func(e event) bool { return e.Id != 2 })
+	got, err := Collect(Map(s, func(e event) (string, error) { return e.Name, nil }))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect() got = %v, want %v", got, want)
+	}
+}
+
+func TestJSONArray(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []event
+		wantErr bool
+	}{
+		{name: "array", body: `[{"id":1,"name":"a"}, {"id":2,"name":"b"}]`, want: []event{{1, "a"}, {2, "b"}}},
+		{name: "empty", body: `[]`},
+		{name: "not array", body: `{"id":1}`, wantErr: true},
+		{name: "broken", body: `[{"id":1},{"id":`, want: []event{{Id: 1}}, wantErr: true},
+		{name: "mismatched close", body: `[{"id":1},{"id":2}}`, want: []event{{Id: 1}, {Id: 2}}, wantErr: true},
+		{name: "trailing data", body: `[{"id":1}] trailing`, want: []event{{Id: 1}}, wantErr: true},
+		{name: "trailing space", body: "[{\"id\":1}]\n", want: []event{{Id: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startBodyServer(http.StatusOK, "application/json", tt.body)
+			defer server.Close()
+
+			got, err := Collect(JSONArray[event](get(t, server.URL)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Collect() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSV(t *testing.T) {
+	server := startBodyServer(http.StatusOK, "text/csv", "1;a\n2;b\n")
+	defer server.Close()
+
+	s := CSV(get(t, server.URL), func(reader *csv.Reader) { reader.Comma = ';' })
+	got, err := Collect(Map(s, func(record []string) (event, error) {
+		id, err := strconv.Atoi(record[0])
+		return event{Id: id, Name: record[1]}, err
+	}))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if want := []event{{1, "a"}, {2, "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect() got = %v, want %v", got, want)
+	}
+}
+
+func TestLines(t *testing.T) {
+	server := startBodyServer(http.StatusOK, "text/plain", "first\r\nsecond\nthird")
+	defer server.Close()
+
+	s := Lines(get(t, server.URL))
+	if !s.Next() || s.Item() != "first" {
+		t.Fatalf("Next() got = %v, want first", s.Item())
+	}
+	// stop early
+	s.Close()
+	if s.Next() {
+		t.Errorf("Next() after Close got = true")
+	}
+
+	got, err := Collect(Lines(get(t, server.URL)))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect() got = %v, want %v", got, want)
+	}
+}
+
+func TestStream_Status(t *testing.T) {
+	server := startBodyServer(http.StatusBadGateway, "text/plain", "bad gateway")
+	defer server.Close()
+
+	_, err := Collect(Lines(get(t, server.URL)))
+	var statusErr *httpx.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Collect() error got = %v, want *httpx.StatusError", err)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	server := startBodyServer(http.StatusOK, "text/plain", "1\n2\n3\n")
+	defer server.Close()
+
+	stop := errors.New("stop")
+	var got []string
+	err := ForEach(Lines(get(t, server.URL)), func(line string) error {
+		got = append(got, line)
+		if line == "2" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("ForEach() got = %v %v, want [1 2] stop", got, err)
+	}
+}
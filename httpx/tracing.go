@@ -0,0 +1,176 @@
+package httpx
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	TraceParentHeader = "Traceparent"
+	TraceStateHeader  = "Tracestate"
+)
+
+// SpanContext is the W3C trace context propagated with requests
+// https://www.w3.org/TR/trace-context/
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Flags      byte
+	TraceState string
+}
+
+// IsValid reports whether both of the ids are not zero
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// IsSampled reports whether the sampled flag is set
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&0x01 != 0
+}
+
+// TraceParent formats the traceparent header value
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), sc.Flags)
+}
+
+// ParseTraceParent parses the traceparent header value
+func ParseTraceParent(traceParent string) (sc SpanContext, err error) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, fmt.Errorf("invalid traceparent: %s", traceParent)
+	}
+	var version, flags [1]byte
+	if err = decodeHex(version[:], parts[0]); err != nil {
+		return sc, fmt.Errorf("invalid traceparent version: %s", err)
+	}
+	if err = decodeHex(sc.TraceID[:], parts[1]); err != nil {
+		return sc, fmt.Errorf("invalid trace id: %s", err)
+	}
+	if err = decodeHex(sc.SpanID[:], parts[2]); err != nil {
+		return sc, fmt.Errorf("invalid span id: %s", err)
+	}
+	if err = decodeHex(flags[:], parts[3]); err != nil {
+		return sc, fmt.Errorf("invalid trace flags: %s", err)
+	}
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("invalid traceparent: %s", traceParent)
+	}
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex of exactly len(dst) bytes
+func decodeHex(dst []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return fmt.Errorf("%s is not %d lowercase hex bytes", s, len(dst))
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// ExtractSpanContext returns the span context of the traceparent and tracestate headers
+func ExtractSpanContext(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceParent(header.Get(TraceParentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = strings.Join(header.Values(TraceStateHeader), ",")
+	return sc, true
+}
+
+// InjectSpanContext sets the traceparent and tracestate headers of the span context
+func InjectSpanContext(header http.Header, sc SpanContext) {
+	if !sc.IsValid() {
+		return
+	}
+	header.Set(TraceParentHeader, sc.TraceParent())
+	if len(sc.TraceState) != 0 {
+		header.Set(TraceStateHeader, sc.TraceState)
+	} else {
+		header.Del(TraceStateHeader)
+	}
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx with the span context
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of ctx, zero SpanContext if none
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Span is a client span of a request
+type Span interface {
+	// SpanContext is injected into the request
+	SpanContext() SpanContext
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts a span for every request made by the client
+// implement it to adapt a tracing library like OpenTelemetry,
+// the parent span is found in ctx by the adapter
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// NoopTracer records nothing and propagates the span context of ctx as it is
+var NoopTracer Tracer = noopTracer{}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{sc: SpanContextFromContext(ctx)}
+}
+
+type noopSpan struct {
+	sc SpanContext
+}
+
+func (s noopSpan) SpanContext() SpanContext           { return s.sc }
+func (s noopSpan) SetAttribute(key string, value any) {}
+func (s noopSpan) RecordError(err error)              {}
+func (s noopSpan) End()                               {}
+
+// WithTracer sets the tracer starting the client spans, NoopTracer by default
+func WithTracer(tracer Tracer) ClientOption {
+	return func(clientOptions *clientOptions) {
+		if tracer == nil {
+			tracer = NoopTracer
+		}
+		clientOptions.tracer = tracer
+	}
+}
+
+// startSpan starts the client span of the attempt and injects its context into hreq
+// a traceparent header set by the request is kept
+func (c *Client) startSpan(hreq *http.Request) (*http.Request, Span) {
+	ctx, span := c.tracer.Start(hreq.Context(), "HTTP "+hreq.Method)
+	span.SetAttribute("http.request.method", hreq.Method)
+	span.SetAttribute("url.full", hreq.URL.String())
+	span.SetAttribute("server.address", hreq.URL.Hostname())
+	if len(hreq.Header.Get(TraceParentHeader)) == 0 {
+		InjectSpanContext(hreq.Header, span.SpanContext())
+	}
+	return hreq.WithContext(ctx), span
+}
+
+// endSpan ends the span with the result of the attempt
+func endSpan(span Span, resp *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetAttribute("http.response.status_code", resp.StatusCode)
+	}
+	span.End()
+}
@@ -0,0 +1,144 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		wantSampled bool
+		wantErr     bool
+	}{
+		{name: "sampled", traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantSampled: true},
+		{name: "not sampled", traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{name: "future version", traceParent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantSampled: true},
+		{name: "zero trace id", traceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "uppercase", traceParent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "invalid version", traceParent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "short span id", traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceParent(tt.traceParent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceParent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if sc.IsSampled() != tt.wantSampled {
+				t.Errorf("IsSampled() got = %v, want %v", sc.IsSampled(), tt.wantSampled)
+			}
+			if tt.traceParent[:2] == "00" && sc.TraceParent() != tt.traceParent {
+				t.Errorf("TraceParent() got = %v, want %v", sc.TraceParent(), tt.traceParent)
+			}
+		})
+	}
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+type testSpan struct {
+	name   string
+	sc     SpanContext
+	attrs  map[string]any
+	ended  bool
+	parent SpanContext
+}
+
+func (tr *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := parent
+	sc.SpanID = [8]byte{byte(len(tr.spans) + 1)}
+	span := &testSpan{name: name, sc: sc, attrs: make(map[string]any), parent: parent}
+	tr.spans = append(tr.spans, span)
+	return ContextWithSpanContext(ctx, sc), span
+}
+
+func (s *testSpan) SpanContext() SpanContext           { return s.sc }
+func (s *testSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *testSpan) RecordError(err error)              { s.attrs["error"] = err }
+func (s *testSpan) End()                               { s.ended = true }
+
+func TestWithTracer(t *testing.T) {
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+	}))
+	defer server.Close()
+
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	parent.TraceState = "vendor=1"
+	ctx := ContextWithSpanContext(context.Background(), parent)
+
+	tests := []struct {
+		name            string
+		tracer          Tracer
+		ctx             context.Context
+		options         []ReqOption
+		wantTraceParent string
+		wantTraceState  string
+	}{
+		{
+			name:            "noop propagates context",
+			ctx:             ctx,
+			wantTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceState:  "vendor=1",
+		},
+		{
+			name: "noop without context",
+			ctx:  context.Background(),
+		},
+		{
+			name:            "client span",
+			tracer:          &testTracer{},
+			ctx:             ctx,
+			wantTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0100000000000000-01",
+			wantTraceState:  "vendor=1",
+		},
+		{
+			name:            "explicit header is kept",
+			ctx:             ctx,
+			options:         []ReqOption{WithHeader("traceparent", "explicit")},
+			wantTraceParent: "explicit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(WithTracer(tt.tracer))
+			options := append(tt.options, WithContext(tt.ctx))
+			res, err := client.Get(server.URL, options...)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			res.Close()
+			if got := gotHeader.Get("traceparent"); got != tt.wantTraceParent {
+				t.Errorf("traceparent got = %v, want %v", got, tt.wantTraceParent)
+			}
+			if got := gotHeader.Get("tracestate"); got != tt.wantTraceState {
+				t.Errorf("tracestate got = %v, want %v", got, tt.wantTraceState)
+			}
+
+			if tracer, ok := tt.tracer.(*testTracer); ok {
+				if len(tracer.spans) != 1 {
+					t.Fatalf("spans got = %d, want 1", len(tracer.spans))
+				}
+				span := tracer.spans[0]
+				if !span.ended || span.name != "HTTP GET" || span.parent != parent {
+					t.Errorf("span got = %+v", span)
+				}
+				if !reflect.DeepEqual(span.attrs["http.response.status_code"], 200) {
+					t.Errorf("span status got = %v, want 200", span.attrs["http.response.status_code"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,564 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of a WebSocket message
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+	CloseMessage  MessageType = 8
+	PingMessage   MessageType = 9
+	PongMessage   MessageType = 10
+
+	continuationFrame = 0
+)
+
+// close codes of RFC 6455
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseNoStatusReceived = 1005
+	CloseMessageTooBig    = 1009
+)
+
+const (
+	DefaultWebSocketCloseTimeout = 5 * time.Second
+	// DefaultWebSocketReadLimit is the max size of a message without a max response size
+	DefaultWebSocketReadLimit = 32 << 20
+	websocketGUID             = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// CloseError is returned by ReadMessage when the peer closed the connection
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if len(e.Reason) != 0 {
+		return fmt.Sprintf("websocket closed: %d %s", e.Code, e.Reason)
+	}
+	return fmt.Sprintf("websocket closed: %d", e.Code)
+}
+
+// ErrWebSocketClosed is returned when the connection is used after Close
+var ErrWebSocketClosed = errors.New("websocket closed")
+
+// errFrameTooLarge is returned by readFrame for a frame longer than the max length
+var errFrameTooLarge = errors.New("frame too large")
+
+type webSocketOptions struct {
+	pingInterval time.Duration
+	closeTimeout time.Duration
+	reqOptions   []ReqOption
+}
+
+type WebSocketOption func(webSocketOptions *webSocketOptions)
+
+func defaultWebSocketOptions() webSocketOptions {
+	return webSocketOptions{
+		closeTimeout: DefaultWebSocketCloseTimeout,
+	}
+}
+
+// WithPingInterval sends a ping every interval and closes the connection
+// if no pong is received until the next ping, 0 disables keepalive(default)
+func WithPingInterval(interval time.Duration) WebSocketOption {
+	return func(webSocketOptions *webSocketOptions) {
+		webSocketOptions.pingInterval = interval
+	}
+}
+
+// WithCloseTimeout sets how long Close waits for the close frame of the peer
+func WithCloseTimeout(timeout time.Duration) WebSocketOption {
+	return func(webSocketOptions *webSocketOptions) {
+		webSocketOptions.closeTimeout = timeout
+	}
+}
+
+// WithWebSocketReqOptions adds options to the handshake request, like headers or queries
+func WithWebSocketReqOptions(options ...ReqOption) WebSocketOption {
+	return func(webSocketOptions *webSocketOptions) {
+		webSocketOptions.reqOptions = append(webSocketOptions.reqOptions, options...)
+	}
+}
+
+// WebSocket is a client connection
+// one goroutine can read and any goroutines can write at the same time
+type WebSocket struct {
+	conn        io.ReadWriteCloser
+	br          *bufio.Reader
+	bodyParsers map[string]BodyParser
+	readLimit   int64
+	// Subprotocol is the protocol selected by the server
+	Subprotocol string
+
+	readMu  sync.Mutex
+	writeMu sync.Mutex
+
+	closeTimeout  time.Duration
+	closeOnce     sync.Once
+	closeSent     bool
+	closed        chan struct{}
+	closeReceived chan struct{}
+	closeRecvOnce sync.Once
+	pong          chan struct{}
+}
+
+// DialWebSocket connects to the ws or wss url, http and https are accepted as well
+// the handshake has the default headers, queries and base url or endpoints of the client,
+// and is made by the transport of the client with its TLS config and timeout
+func (c *Client) DialWebSocket(ctx context.Context, url string, options ...WebSocketOption) (ws *WebSocket, err error) {
+	wo := defaultWebSocketOptions()
+	for _, opt := range options {
+		opt(&wo)
+	}
+
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %v", err)
+	}
+	req := newRequest()
+	req.ctx = ctx
+	for _, option := range wo.reqOptions {
+		if err = option(req); err != nil {
+			return
+		}
+	}
+
+	// the endpoint is picked for the handshake like Do, the connection is not counted as outstanding
+	base := c.baseURL
+	var ep *endpoint
+	if c.balancer != nil && !u.IsAbs() && len(u.Host) == 0 {
+		ep = c.balancer.pick(nil)
+		base = ep.url
+	}
+	result := attemptNotSent
+	if ep != nil {
+		defer func() {
+			c.balancer.done(ep, result)
+		}()
+	}
+
+	hreq, err := c.newHTTPRequest(http.MethodGet, base, u, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(hreq.URL.Scheme) {
+	case "ws", "http":
+		hreq.URL.Scheme = "http"
+	case "wss", "https":
+		hreq.URL.Scheme = "https"
+	default:
+		return nil, fmt.Errorf("unsupported scheme: %s", hreq.URL.Scheme)
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err = rand.Read(keyBytes); err != nil {
+		return nil, fmt.Errorf("failed to create key: %s", err)
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+	hreq.Header.Set("Connection", "Upgrade")
+	hreq.Header.Set("Upgrade", "websocket")
+	hreq.Header.Set("Sec-WebSocket-Version", "13")
+	hreq.Header.Set("Sec-WebSocket-Key", key)
+
+	// the timeout of the client is for the handshake only
+	handshakeCtx := ctx
+	if c.client.Timeout > 0 {
+		var cancel context.CancelFunc
+		handshakeCtx, cancel = context.WithTimeout(ctx, c.client.Timeout)
+		defer cancel()
+	}
+	resp, err := c.client.Transport.RoundTrip(hreq.WithContext(handshakeCtx))
+	result = resultOf(handshakeCtx, resp, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request: %s", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, fmt.Errorf("connection is not upgraded")
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("invalid handshake response")
+	}
+
+	ws = &WebSocket{
+		conn:          conn,
+		br:            bufio.NewReader(conn),
+		bodyParsers:   make(map[string]BodyParser),
+		Subprotocol:   resp.Header.Get("Sec-WebSocket-Protocol"),
+		closeTimeout:  wo.closeTimeout,
+		closed:        make(chan struct{}),
+		closeReceived: make(chan struct{}),
+		pong:          make(chan struct{}, 1),
+	}
+	for k, v := range c.bodyParsers {
+		ws.bodyParsers[k] = v
+	}
+	for k, v := range req.bodyParser {
+		ws.bodyParsers[k] = v
+	}
+	ws.readLimit = c.maxResponseSize
+	if req.maxResponseSize != 0 {
+		ws.readLimit = req.maxResponseSize
+	}
+	if ws.readLimit <= 0 {
+		ws.readLimit = DefaultWebSocketReadLimit
+	}
+	if wo.pingInterval > 0 {
+		go ws.keepalive(wo.pingInterval)
+	}
+	return ws, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// ReadMessage reads the next text or binary message
+// pings are answered, and *CloseError is returned when the peer closes
+// a message larger than the max response size of the client fails,
+// or than DefaultWebSocketReadLimit without the max response size
+func (ws *WebSocket) ReadMessage() (MessageType, []byte, error) {
+	ws.readMu.Lock()
+	defer ws.readMu.Unlock()
+	return ws.readMessage()
+}
+
+func (ws *WebSocket) readMessage() (MessageType, []byte, error) {
+	var messageType MessageType
+	var message []byte
+	for {
+		f, err := readFrame(ws.br, ws.readLimit-int64(len(message)))
+		if errors.Is(err, errFrameTooLarge) {
+			ws.fail(CloseMessageTooBig, "")
+			return 0, nil, &ResponseTooLargeError{Limit: ws.readLimit, ContentLength: -1}
+		}
+		if err != nil {
+			ws.closeConn()
+			return 0, nil, err
+		}
+		if f.masked {
+			ws.fail(CloseProtocolError, "masked frame")
+			return 0, nil, fmt.Errorf("masked frame from server")
+		}
+
+		switch f.opcode {
+		case PingMessage:
+			ws.writeFrame(PongMessage, f.payload)
+			continue
+		case PongMessage:
+			select {
+			case ws.pong <- struct{}{}:
+			default:
+			}
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNoStatusReceived}
+			if len(f.payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(f.payload))
+				closeErr.Reason = string(f.payload[2:])
+			}
+			ws.closeRecvOnce.Do(func() { close(ws.closeReceived) })
+			// echo the close code
+			ws.sendClose(closeErr.Code, "")
+			ws.closeConn()
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				ws.fail(CloseProtocolError, "unexpected data frame")
+				return 0, nil, fmt.Errorf("unexpected data frame in a fragmented message")
+			}
+			messageType = f.opcode
+		case continuationFrame:
+			if messageType == 0 {
+				ws.fail(CloseProtocolError, "unexpected continuation frame")
+				return 0, nil, fmt.Errorf("unexpected continuation frame")
+			}
+		default:
+			ws.fail(CloseProtocolError, "unknown opcode")
+			return 0, nil, fmt.Errorf("unknown opcode %d", f.opcode)
+		}
+
+		message = append(message, f.payload...)
+		if f.fin {
+			if messageType == TextMessage && !utf8.Valid(message) {
+				ws.fail(CloseProtocolError, "invalid utf-8")
+				return 0, nil, fmt.Errorf("invalid utf-8 text message")
+			}
+			return messageType, message, nil
+		}
+	}
+}
+
+// ReadJSON reads a message and parses it with the application/json body parser
+func (ws *WebSocket) ReadJSON(v any) error {
+	return ws.ReadMessageAs("application/json", v)
+}
+
+// ReadMessageAs reads a message and parses it with the body parser of the content type
+func (ws *WebSocket) ReadMessageAs(contentType string, v any) error {
+	parser, ok := ws.bodyParsers[contentType]
+	if !ok {
+		return fmt.Errorf("no parser found for %s", contentType)
+	}
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return parser(bytes.NewReader(data), v)
+}
+
+// WriteMessage writes a text or binary message
+func (ws *WebSocket) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("invalid message type %d", messageType)
+	}
+	return ws.writeFrame(messageType, data)
+}
+
+// WriteText writes a text message
+func (ws *WebSocket) WriteText(text string) error {
+	return ws.writeFrame(TextMessage, []byte(text))
+}
+
+// WriteJSON writes v as a json text message
+func (ws *WebSocket) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.writeFrame(TextMessage, data)
+}
+
+// Ping sends a ping
+func (ws *WebSocket) Ping(data []byte) error {
+	return ws.writeFrame(PingMessage, data)
+}
+
+func (ws *WebSocket) writeFrame(opcode MessageType, payload []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	if ws.closeSent {
+		return ErrWebSocketClosed
+	}
+	return writeFrame(ws.conn, opcode, payload, true)
+}
+
+// sendClose sends the close frame once, the code is omitted if CloseNoStatusReceived
+func (ws *WebSocket) sendClose(code int, reason string) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	if ws.closeSent {
+		return nil
+	}
+	ws.closeSent = true
+	var payload []byte
+	if code != CloseNoStatusReceived {
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+	return writeFrame(ws.conn, CloseMessage, payload, true)
+}
+
+// fail closes the connection without waiting for the peer
+func (ws *WebSocket) fail(code int, reason string) {
+	ws.sendClose(code, reason)
+	ws.closeConn()
+}
+
+func (ws *WebSocket) closeConn() {
+	ws.closeOnce.Do(func() {
+		close(ws.closed)
+		ws.conn.Close()
+	})
+}
+
+// Close closes the connection gracefully with CloseNormalClosure
+func (ws *WebSocket) Close() error {
+	return ws.CloseWith(CloseNormalClosure, "")
+}
+
+// CloseWith sends the close frame and waits for the close frame of the peer until the close timeout
+func (ws *WebSocket) CloseWith(code int, reason string) error {
+	select {
+	case <-ws.closed:
+		return nil
+	default:
+	}
+	err := ws.sendClose(code, reason)
+
+	timer := time.AfterFunc(ws.closeTimeout, ws.closeConn)
+	defer timer.Stop()
+	if ws.readMu.TryLock() {
+		// nobody is reading, discard messages until the close frame
+		for {
+			if _, _, readErr := ws.readMessage(); readErr != nil {
+				break
+			}
+		}
+		ws.readMu.Unlock()
+	} else {
+		select {
+		case <-ws.closeReceived:
+		case <-ws.closed:
+		}
+	}
+	ws.closeConn()
+	return err
+}
+
+func (ws *WebSocket) keepalive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	waiting := false
+	for {
+		select {
+		case <-ws.closed:
+			return
+		case <-ws.pong:
+			waiting = false
+		case <-ticker.C:
+			if waiting {
+				// no pong since the last ping
+				ws.closeConn()
+				return
+			}
+			if err := ws.Ping(nil); err != nil {
+				return
+			}
+			waiting = true
+		}
+	}
+}
+
+type frame struct {
+	fin     bool
+	opcode  MessageType
+	masked  bool
+	payload []byte
+}
+
+// readFrame reads a frame and unmasks the payload
+// errFrameTooLarge is returned before reading the payload of a data frame longer than maxLength
+func readFrame(r io.Reader, maxLength int64) (f frame, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	f.fin = header[0]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		return f, fmt.Errorf("reserved bits are set")
+	}
+	f.opcode = MessageType(header[0] & 0x0f)
+	f.masked = header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if f.opcode >= CloseMessage && (length > 125 || !f.fin) {
+		return f, fmt.Errorf("invalid control frame")
+	}
+	if f.opcode < CloseMessage && (maxLength < 0 || length > uint64(maxLength)) {
+		return f, errFrameTooLarge
+	}
+
+	var mask [4]byte
+	if f.masked {
+		if _, err = io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+	}
+	f.payload = make([]byte, length)
+	if _, err = io.ReadFull(r, f.payload); err != nil {
+		return
+	}
+	if f.masked {
+		maskBytes(mask, f.payload)
+	}
+	return f, nil
+}
+
+// writeFrame writes a single final frame, clients mask the payload
+func writeFrame(w io.Writer, opcode MessageType, payload []byte, masked bool) error {
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|byte(opcode))
+
+	var maskBit byte
+	if masked {
+		maskBit = 0x80
+	}
+	switch length := len(payload); {
+	case length <= 125:
+		buf = append(buf, maskBit|byte(length))
+	case length <= 0xffff:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(length))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(length))
+	}
+
+	if masked {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		buf = append(buf, mask[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		maskBytes(mask, buf[start:])
+	} else {
+		buf = append(buf, payload...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func maskBytes(mask [4]byte, data []byte) {
+	for i := range data {
+		data[i] ^= mask[i%4]
+	}
+}
@@ -0,0 +1,286 @@
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startWebSocketServer accepts websocket connections and runs handler with the raw connection
+func startWebSocketServer(handler func(r *http.Request, rw *bufio.ReadWriter)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+		handler(r, rw)
+	}))
+}
+
+// serverWrite writes an unmasked frame
+func serverWrite(rw *bufio.ReadWriter, opcode MessageType, payload []byte) {
+	writeFrame(rw, opcode, payload, false)
+	rw.Flush()
+}
+
+func echoHandler(r *http.Request, rw *bufio.ReadWriter) {
+	for {
+		f, err := readFrame(rw, DefaultWebSocketReadLimit)
+		if err != nil || !f.masked {
+			return
+		}
+		switch f.opcode {
+		case PingMessage:
+			serverWrite(rw, PongMessage, f.payload)
+		case CloseMessage:
+			serverWrite(rw, CloseMessage, f.payload)
+			return
+		default:
+			serverWrite(rw, f.opcode, f.payload)
+		}
+	}
+}
+
+func TestWebSocket_Echo(t *testing.T) {
+	server := startWebSocketServer(echoHandler)
+	defer server.Close()
+
+	ws, err := NewClient().DialWebSocket(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+
+	if err = ws.WriteText("hello"); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	messageType, data, err := ws.ReadMessage()
+	if err != nil || messageType != TextMessage || string(data) != "hello" {
+		t.Errorf("ReadMessage() got = %v %q %v, want text hello", messageType, data, err)
+	}
+
+	large := []byte(strings.Repeat("x", 70000))
+	ws.WriteMessage(BinaryMessage, large)
+	messageType, data, err = ws.ReadMessage()
+	if err != nil || messageType != BinaryMessage || len(data) != len(large) {
+		t.Errorf("ReadMessage() got = %v %d bytes %v, want binary %d bytes", messageType, len(data), err, len(large))
+	}
+
+	type message struct {
+		Name string `json:"name"`
+	}
+	ws.WriteJSON(message{Name: "json"})
+	var got message
+	if err = ws.ReadJSON(&got); err != nil || got.Name != "json" {
+		t.Errorf("ReadJSON() got = %v %v, want json", got, err)
+	}
+
+	if err = ws.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err = ws.WriteText("after close"); err != ErrWebSocketClosed {
+		t.Errorf("WriteText() after close error got = %v, want %v", err, ErrWebSocketClosed)
+	}
+}
+
+func TestWebSocket_Handshake(t *testing.T) {
+	server := startWebSocketServer(func(r *http.Request, rw *bufio.ReadWriter) {
+		serverWrite(rw, TextMessage, []byte(r.URL.Path+"?"+r.URL.RawQuery+" "+r.Header.Get("X-Api-Key")))
+		// the client answers the ping while reading
+		serverWrite(rw, PingMessage, []byte("p"))
+		if f, err := readFrame(rw, DefaultWebSocketReadLimit); err == nil && f.opcode == PongMessage {
+			serverWrite(rw, TextMessage, []byte("pong "+string(f.payload)))
+		}
+		payload := binary.BigEndian.AppendUint16(nil, CloseGoingAway)
+		serverWrite(rw, CloseMessage, append(payload, "bye"...))
+		readFrame(rw, DefaultWebSocketReadLimit)
+	})
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL+"/api"), WithDefaultHeader("X-Api-Key", "secret"))
+	ws, err := client.DialWebSocket(context.Background(), "/events",
+		WithWebSocketReqOptions(WithQuery("topic", "a")))
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+	defer ws.Close()
+
+	for _, want := range []string{"/api/events?topic=a secret", "pong p"} {
+		if _, data, err := ws.ReadMessage(); err != nil || string(data) != want {
+			t.Errorf("ReadMessage() got = %q %v, want %q", data, err, want)
+		}
+	}
+	_, _, err = ws.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != CloseGoingAway || closeErr.Reason != "bye" {
+		t.Errorf("ReadMessage() error got = %v, want close 1001 bye", err)
+	}
+}
+
+func TestWebSocket_Endpoints(t *testing.T) {
+	server := startWebSocketServer(func(r *http.Request, rw *bufio.ReadWriter) {
+		serverWrite(rw, TextMessage, []byte(r.URL.Path))
+		readFrame(rw, DefaultWebSocketReadLimit)
+	})
+	defer server.Close()
+
+	client := NewClient(WithEndpoints([]string{"http://127.0.0.1:1", server.URL}, RoundRobin))
+	// each endpoint is dialed in turn, the closed port fails the handshake
+	dialed := 0
+	for i := 0; i < 2; i++ {
+		ws, err := client.DialWebSocket(context.Background(), "/events")
+		if err != nil {
+			continue
+		}
+		dialed++
+		if _, data, err := ws.ReadMessage(); err != nil || string(data) != "/events" {
+			t.Errorf("ReadMessage() got = %q %v, want /events", data, err)
+		}
+		ws.Close()
+	}
+	if dialed != 1 {
+		t.Errorf("DialWebSocket() dialed got = %d, want 1", dialed)
+	}
+	for _, ep := range client.balancer.endpoints {
+		if ep.outstanding != 0 {
+			t.Errorf("outstanding of %s got = %d, want 0", ep.url, ep.outstanding)
+		}
+	}
+}
+
+func TestWebSocket_NotUpgraded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewClient().DialWebSocket(context.Background(), server.URL)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("DialWebSocket() error got = %v, want 401 StatusError", err)
+	}
+}
+
+func TestWebSocket_Keepalive(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(r *http.Request, rw *bufio.ReadWriter)
+		wantClosed bool
+	}{
+		{name: "pong received", handler: echoHandler},
+		{
+			name: "no pong",
+			handler: func(r *http.Request, rw *bufio.ReadWriter) {
+				for {
+					if _, err := readFrame(rw, DefaultWebSocketReadLimit); err != nil {
+						return
+					}
+				}
+			},
+			wantClosed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startWebSocketServer(tt.handler)
+			defer server.Close()
+
+			ws, err := NewClient().DialWebSocket(context.Background(), server.URL,
+				WithPingInterval(20*time.Millisecond), WithCloseTimeout(100*time.Millisecond))
+			if err != nil {
+				t.Fatalf("DialWebSocket() error = %v", err)
+			}
+			defer ws.Close()
+
+			done := make(chan error, 1)
+			go func() {
+				_, _, err := ws.ReadMessage()
+				done <- err
+			}()
+			select {
+			case <-done:
+				if !tt.wantClosed {
+					t.Errorf("ReadMessage() returned, want keepalive")
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantClosed {
+					t.Errorf("ReadMessage() not closed without pong")
+				}
+			}
+		})
+	}
+}
+
+func TestWebSocket_ReadLimit(t *testing.T) {
+	// hugeFrame has a header of 1 TiB payload without the payload
+	hugeFrame := binary.BigEndian.AppendUint64([]byte{0x80 | byte(BinaryMessage), 127}, 1<<40)
+	tests := []struct {
+		name      string
+		client    *Client
+		frame     []byte
+		wantLimit int64
+	}{
+		{
+			name:      "message over max response size",
+			client:    NewClient(WithDefaultMaxResponseSize(10)),
+			frame:     append([]byte{0x80 | byte(TextMessage), 100}, strings.Repeat("x", 100)...),
+			wantLimit: 10,
+		},
+		{
+			name:      "huge frame over max response size",
+			client:    NewClient(WithDefaultMaxResponseSize(10)),
+			frame:     hugeFrame,
+			wantLimit: 10,
+		},
+		{
+			name:      "huge frame without max response size",
+			client:    NewClient(),
+			frame:     hugeFrame,
+			wantLimit: DefaultWebSocketReadLimit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closeCode := make(chan int, 1)
+			server := startWebSocketServer(func(r *http.Request, rw *bufio.ReadWriter) {
+				rw.Write(tt.frame)
+				rw.Flush()
+				if f, err := readFrame(rw, DefaultWebSocketReadLimit); err == nil && len(f.payload) >= 2 {
+					closeCode <- int(binary.BigEndian.Uint16(f.payload))
+				}
+			})
+			defer server.Close()
+
+			ws, err := tt.client.DialWebSocket(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("DialWebSocket() error = %v", err)
+			}
+			defer ws.Close()
+			var tooLarge *ResponseTooLargeError
+			if _, _, err = ws.ReadMessage(); !errors.As(err, &tooLarge) || tooLarge.Limit != tt.wantLimit {
+				t.Fatalf("ReadMessage() error got = %v, want *ResponseTooLargeError of %d", err, tt.wantLimit)
+			}
+			select {
+			case code := <-closeCode:
+				if code != CloseMessageTooBig {
+					t.Errorf("ReadMessage() close code got = %d, want %d", code, CloseMessageTooBig)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("ReadMessage() close frame not sent")
+			}
+		})
+	}
+}
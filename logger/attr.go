@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// badKey is the key of a value without a key
+const badKey = "!BADKEY"
+
+// Attr is a key value pair of a log record
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns an Attr for a string value
+func String(key, value string) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int returns an Attr for an int value
+func Int(key string, value int) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int64 returns an Attr for an int64 value
+func Int64(key string, value int64) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Uint64 returns an Attr for an uint64 value
+func Uint64(key string, value uint64) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Float64 returns an Attr for a float64 value
+func Float64(key string, value float64) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Bool returns an Attr for a bool value
+func Bool(key string, value bool) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Duration returns an Attr for a time.Duration value
+func Duration(key string, value time.Duration) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Time returns an Attr for a time.Time value
+func Time(key string, value time.Time) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Err returns an Attr for an error with the key error
+func Err(err error) Attr {
+	return Attr{Key: "error", Value: err}
+}
+
+// Any returns an Attr for any value
+func Any(key string, value any) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Record is a log message with its attributes
+type Record struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+	Attrs   []Attr
+	// Caller is zero unless Logger.SetCaller
+	Caller Caller
+	// GoroutineID is 0 unless Logger.SetGoroutineID
+	GoroutineID uint64
+	// Stack is empty unless Logger.SetStackTrace for ErrorLevel and PanicLevel
+	Stack string
+}
+
+// argsToAttrs converts args of alternating keys and values to attrs
+// an Attr is taken as it is, a slog.Attr is flattened, a value without a string key has the key !BADKEY
+func argsToAttrs(args []any) []Attr {
+	var attrs []Attr
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case Attr:
+			attrs = append(attrs, arg)
+		case slog.Attr:
+			attrs = appendSlogAttr(attrs, "", arg)
+		case string:
+			if i+1 < len(args) {
+				attrs = append(attrs, Attr{Key: arg, Value: args[i+1]})
+				i++
+			} else {
+				attrs = append(attrs, Attr{Key: badKey, Value: arg})
+			}
+		default:
+			attrs = append(attrs, Attr{Key: badKey, Value: arg})
+		}
+	}
+	return attrs
+}
+
+// formatValue returns the text of the value, quoted if it has spaces or special characters
+func formatValue(value any) string {
+	var s string
+	switch v := value.(type) {
+	case time.Time:
+		s = v.Format(time.RFC3339)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuoting(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, r := range s {
+		if r == utf8.RuneError || r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
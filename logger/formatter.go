@@ -0,0 +1,239 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// time formats of WithTimeFormat besides the layouts of time.Format
+const (
+	// TimeFormatUnix formats the time as seconds since the Unix epoch
+	TimeFormatUnix = "unix"
+	// TimeFormatUnixMilli formats the time as milliseconds since the Unix epoch
+	TimeFormatUnixMilli = "unixms"
+)
+
+type formatterOptions struct {
+	timeKey      string
+	levelKey     string
+	messageKey   string
+	callerKey    string
+	goroutineKey string
+	stackKey     string
+	timeFormat   string
+}
+
+func defaultFormatterOptions() *formatterOptions {
+	return &formatterOptions{
+		timeKey:      "time",
+		levelKey:     "level",
+		messageKey:   "msg",
+		callerKey:    "caller",
+		goroutineKey: "goroutine",
+		stackKey:     "stack",
+		timeFormat:   time.RFC3339,
+	}
+}
+
+type FormatterOption func(*formatterOptions)
+
+// WithTimeKey sets the key of the time, time by default, empty to omit
+func WithTimeKey(key string) FormatterOption {
+	return func(options *formatterOptions) {
+		options.timeKey = key
+	}
+}
+
+// WithLevelKey sets the key of the level, level by default, empty to omit
+func WithLevelKey(key string) FormatterOption {
+	return func(options *formatterOptions) {
+		options.levelKey = key
+	}
+}
+
+// WithMessageKey sets the key of the message, msg by default, empty to omit
+func WithMessageKey(key string) FormatterOption {
+	return func(options *formatterOptions) {
+		options.messageKey = key
+	}
+}
+
+// WithCallerKey sets the key of the caller as file:line, caller by default, empty to omit
+func WithCallerKey(key string) FormatterOption {
+	return func(options *formatterOptions) {
+		options.callerKey = key
+	}
+}
+
+// WithGoroutineKey sets the key of the goroutine id, goroutine by default, empty to omit
+func WithGoroutineKey(key string) FormatterOption {
+	return func(options *formatterOptions) {
+		options.goroutineKey = key
+	}
+}
+
+// WithStackKey sets the key of the stack trace, stack by default, empty to omit
+func WithStackKey(key string) FormatterOption {
+	return func(options *formatterOptions) {
+		options.stackKey = key
+	}
+}
+
+// WithTimeFormat sets the layout of the time like time.RFC3339Nano, time.RFC3339 by default
+// TimeFormatUnix and TimeFormatUnixMilli format the time as a number
+func WithTimeFormat(format string) FormatterOption {
+	return func(options *formatterOptions) {
+		options.timeFormat = format
+	}
+}
+
+func newFormatterOptions(options []FormatterOption) *formatterOptions {
+	fo := defaultFormatterOptions()
+	for _, opt := range options {
+		opt(fo)
+	}
+	return fo
+}
+
+// fields returns the fields of the record in order, the omitted or not recorded ones are left out
+// time, level, message, caller, goroutine, the attributes and stack
+func (o *formatterOptions) fields(record Record) []Attr {
+	if o == nil {
+		o = defaultFormatterOptions()
+	}
+	var fields []Attr
+	if len(o.timeKey) != 0 {
+		fields = append(fields, Attr{Key: o.timeKey, Value: o.formatTime(record.Time)})
+	}
+	if len(o.levelKey) != 0 {
+		fields = append(fields, Attr{Key: o.levelKey, Value: record.Level.String()})
+	}
+	if len(o.messageKey) != 0 {
+		fields = append(fields, Attr{Key: o.messageKey, Value: record.Message})
+	}
+	if len(o.callerKey) != 0 && !record.Caller.IsZero() {
+		fields = append(fields, Attr{Key: o.callerKey, Value: record.Caller.String()})
+	}
+	if len(o.goroutineKey) != 0 && record.GoroutineID != 0 {
+		fields = append(fields, Attr{Key: o.goroutineKey, Value: record.GoroutineID})
+	}
+	fields = append(fields, record.Attrs...)
+	if len(o.stackKey) != 0 && len(record.Stack) != 0 {
+		fields = append(fields, Attr{Key: o.stackKey, Value: record.Stack})
+	}
+	return fields
+}
+
+func (o *formatterOptions) formatTime(t time.Time) any {
+	switch o.timeFormat {
+	case TimeFormatUnix:
+		return t.Unix()
+	case TimeFormatUnixMilli:
+		return t.UnixMilli()
+	default:
+		return t.Format(o.timeFormat)
+	}
+}
+
+type JSONFormatter struct {
+	options *formatterOptions
+}
+
+// NewJSONFormatter creates a new log formatter that formats a record as a json object
+//
+//	{"time":"2006-01-02T15:04:05Z","level":"INF","msg":"request","user":"id"}
+func NewJSONFormatter(options ...FormatterOption) LogFormatter {
+	return &JSONFormatter{options: newFormatterOptions(options)}
+}
+
+func (f *JSONFormatter) Format(record Record) string {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, field := range f.options.fields(record) {
+		if i != 0 {
+			buf.WriteString(",")
+		}
+		writeJSONField(&buf, field.Key, jsonValue(field.Value))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value any) {
+	data, err := marshalJSON(key)
+	if err != nil {
+		data = []byte(`""`)
+	}
+	buf.Write(data)
+	buf.WriteString(":")
+	if data, err = marshalJSON(value); err != nil {
+		// not marshaled like a channel, a func or NaN
+		data, _ = marshalJSON(fmt.Sprint(value))
+	}
+	buf.Write(data)
+}
+
+// marshalJSON marshals the value without escaping <, > and &
+func marshalJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// jsonValue returns the value to marshal, errors and durations as text
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+type LogfmtFormatter struct {
+	options *formatterOptions
+}
+
+// NewLogfmtFormatter creates a new log formatter that formats a record as logfmt key=value pairs
+// values with spaces or special characters are quoted and escaped
+//
+//	time=2006-01-02T15:04:05Z level=INF msg="a request" user=id
+func NewLogfmtFormatter(options ...FormatterOption) LogFormatter {
+	return &LogfmtFormatter{options: newFormatterOptions(options)}
+}
+
+func (f *LogfmtFormatter) Format(record Record) string {
+	var sb strings.Builder
+	for i, field := range f.options.fields(record) {
+		if i != 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(logfmtKey(field.Key))
+		sb.WriteString("=")
+		sb.WriteString(formatValue(field.Value))
+	}
+	return sb.String()
+}
+
+// logfmtKey replaces the characters not allowed in a key with _
+func logfmtKey(key string) string {
+	if len(key) == 0 {
+		return badKey
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
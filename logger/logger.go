@@ -1,11 +1,13 @@
-// Description: A simple logger package that wraps slog with colored output.
+// Description: A simple logger package with leveled, structured and colored output.
 package logger
 
 import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -72,8 +74,34 @@ const (
 const DefaultTimeFormatPattern = "2006-01-02 15:04:05"
 
 // LogFormatter is an interface for log formatters
+// the formatted record is written with a newline
 type LogFormatter interface {
-	Format(logTime time.Time, level LogLevel, msg string) string
+	Format(record Record) string
+}
+
+// appendRecord appends the attrs, caller and goroutine of the record as key=value to the text
+// and the stack trace in the following lines
+func appendRecord(text string, record Record) string {
+	var sb strings.Builder
+	sb.WriteString(text)
+	attrs := record.Attrs
+	if !record.Caller.IsZero() {
+		attrs = append(attrs[:len(attrs):len(attrs)], String("caller", record.Caller.String()))
+	}
+	if record.GoroutineID != 0 {
+		attrs = append(attrs[:len(attrs):len(attrs)], Uint64("goroutine", record.GoroutineID))
+	}
+	for _, attr := range attrs {
+		sb.WriteString(" ")
+		sb.WriteString(attr.Key)
+		sb.WriteString("=")
+		sb.WriteString(formatValue(attr.Value))
+	}
+	if len(record.Stack) != 0 {
+		sb.WriteString("\n")
+		sb.WriteString(record.Stack)
+	}
+	return sb.String()
 }
 
 type DefaultFormatter struct{}
@@ -83,8 +111,9 @@ func NewDefaultFormatter() LogFormatter {
 	return &DefaultFormatter{}
 }
 
-func (f *DefaultFormatter) Format(logTime time.Time, level LogLevel, msg string) string {
-	return fmt.Sprintf("%s %s %s", logTime.Format(DefaultTimeFormatPattern), level.String(), msg)
+func (f *DefaultFormatter) Format(record Record) string {
+	logMsg := fmt.Sprintf("%s %s %s", record.Time.Format(DefaultTimeFormatPattern), record.Level.String(), record.Message)
+	return appendRecord(logMsg, record)
 }
 
 type ColoredLevelFormatter struct{}
@@ -94,9 +123,9 @@ func NewColoredLevelFormatter() LogFormatter {
 	return &ColoredLevelFormatter{}
 }
 
-func (f *ColoredLevelFormatter) Format(logTime time.Time, level LogLevel, msg string) string {
+func (f *ColoredLevelFormatter) Format(record Record) string {
 	var color string
-	switch level {
+	switch record.Level {
 	case VerboseLevel:
 		color = white
 	case DebugLevel:
@@ -114,26 +143,38 @@ func (f *ColoredLevelFormatter) Format(logTime time.Time, level LogLevel, msg st
 	}
 
 	//timeStr := logTime.Format(time.RFC3339)
-	timeStr := logTime.Format(DefaultTimeFormatPattern)
+	timeStr := record.Time.Format(DefaultTimeFormatPattern)
 	//coloredLevelStr := fmt.Sprintf("%s%s%s", color, level.String(), reset)
 	//logMsg := fmt.Sprintf("%s %s %s", timeStr, coloredLevelStr, msg)
-	logMsg := fmt.Sprintf("%s %s%s%s %s", timeStr, color, level.String(), reset, msg)
-	return logMsg
+	logMsg := fmt.Sprintf("%s %s%s%s %s", timeStr, color, record.Level.String(), reset, record.Message)
+	return appendRecord(logMsg, record)
 }
 
 type Logger struct {
-	ctx       context.Context
-	mu        sync.Mutex
+	ctx context.Context
+	// mu is shared with the child loggers
+	mu        *sync.Mutex
 	wr        io.Writer
 	logLevel  LogLevel
 	formatter LogFormatter
+	// handler is the backend instead of the formatter and the writer if not nil
+	handler slog.Handler
+	// attrs are added to every message, with the group prefix if no handler
+	attrs []Attr
+	// group is the prefix of the keys like group.
+	group string
+	// caller, goroutineID and stackTrace add the information to records
+	caller      bool
+	callerSkip  int
+	goroutineID bool
+	stackTrace  bool
 }
 
 // NewLogger creates a new logger with the given writer
 func NewLogger(wr io.Writer) *Logger {
 	logger := &Logger{
 		ctx: context.Background(),
-		mu:  sync.Mutex{},
+		mu:  &sync.Mutex{},
 		wr:  wr,
 		logLevel: func() LogLevel {
 			logLevel := DebugLevel
@@ -169,6 +210,13 @@ func NewLoggerWithFormatter(wr io.Writer, formatter LogFormatter) *Logger {
 	return logger
 }
 
+// NewLoggerWithHandler creates a new logger that sends messages to the slog handler
+func NewLoggerWithHandler(handler slog.Handler) *Logger {
+	logger := NewLogger(nil)
+	logger.SetHandler(handler)
+	return logger
+}
+
 // GetLogger returns the default logger
 func GetLogger() *Logger {
 	return defaultLogger
@@ -182,6 +230,32 @@ func (c *Logger) SetFormatter(formatter LogFormatter) {
 	c.formatter = formatter
 }
 
+// SetHandler sends messages to the slog handler instead of the formatter and the writer
+// nil sets back the formatter
+func (c *Logger) SetHandler(handler slog.Handler) {
+	c.handler = handler
+}
+
+// SetCaller records the file, line and function of the caller of the logging functions
+func (c *Logger) SetCaller(enabled bool) {
+	c.caller = enabled
+}
+
+// SetCallerSkip skips more frames to find the caller, like 1 for a wrapper of the logger
+func (c *Logger) SetCallerSkip(skip int) {
+	c.callerSkip = skip
+}
+
+// SetGoroutineID records the id of the goroutine that logs
+func (c *Logger) SetGoroutineID(enabled bool) {
+	c.goroutineID = enabled
+}
+
+// SetStackTrace records the stack trace of the caller for messages of ErrorLevel and PanicLevel
+func (c *Logger) SetStackTrace(enabled bool) {
+	c.stackTrace = enabled
+}
+
 func (c *Logger) SetWriter(wr io.Writer) {
 	Debugf("Setting writer to %v\n", wr)
 	c.mu.Lock()
@@ -198,18 +272,199 @@ func (c *Logger) Write(p []byte) (n int, err error) {
 	return 0, nil
 }
 
-// Log logs a message with the given log level
-func Log(ctx context.Context, level LogLevel, msg string, args ...any) {
-	if defaultLogger.logLevel > level {
+// With returns a child logger that adds the attributes to every message
+// args are alternating keys and values or Attrs, as of Log
+// the child has the writer, level, formatter and handler of the logger at the time
+func (c *Logger) With(args ...any) *Logger {
+	child := c.clone()
+	attrs := argsToAttrs(args)
+	if len(attrs) == 0 {
+		return child
+	}
+	if c.handler != nil {
+		child.handler = c.handler.WithAttrs(slogAttrs(attrs))
+	} else {
+		child.attrs = append(append([]Attr{}, c.attrs...), c.prefixed(attrs)...)
+	}
+	return child
+}
+
+func (c *Logger) clone() *Logger {
+	return &Logger{
+		ctx:       c.ctx,
+		mu:        c.mu,
+		wr:        c.wr,
+		logLevel:  c.logLevel,
+		formatter: c.formatter,
+		handler:   c.handler,
+		attrs:     c.attrs,
+		group:     c.group,
+
+		caller:      c.caller,
+		callerSkip:  c.callerSkip,
+		goroutineID: c.goroutineID,
+		stackTrace:  c.stackTrace,
+	}
+}
+
+// prefixed returns the attrs with the keys prefixed by the group
+func (c *Logger) prefixed(attrs []Attr) []Attr {
+	if len(c.group) == 0 {
+		return attrs
+	}
+	result := make([]Attr, len(attrs))
+	for i, attr := range attrs {
+		result[i] = Attr{Key: c.group + attr.Key, Value: attr.Value}
+	}
+	return result
+}
+
+// enabled reports whether a message of the level is logged
+// it is not exported, Enabled of slog.Handler is the exported check
+func (c *Logger) enabled(level LogLevel) bool {
+	return c.logLevel <= level
+}
+
+// log logs the message formatted with args if format, or with args as attributes
+// it is called by the logging functions directly, so the caller is at a fixed depth
+func (c *Logger) log(ctx context.Context, level LogLevel, msg string, args []any, format bool) {
+	if !c.enabled(level) {
+		return
+	}
+	record := Record{
+		Time:  time.Now(),
+		Level: level,
+	}
+	if format {
+		msg = fmt.Sprintf(msg, args...)
+	} else {
+		record.Attrs = argsToAttrs(args)
+	}
+	record.Message = strings.TrimSuffix(msg, "\n")
+
+	var pcs []uintptr
+	if c.handler != nil || c.caller || c.stackEnabled(level) {
+		// runtime.Callers, callers, log and the logging function
+		pcs = callers(4 + c.callerSkip)
+	}
+	c.output(ctx, record, pcs)
+}
+
+// output sends the record to the handler, or writes it formatted with the attrs of the logger
+// pcs are the program counters from the caller of the logging function
+func (c *Logger) output(ctx context.Context, record Record, pcs []uintptr) {
+	c.addSource(&record, pcs)
+	if c.handler != nil {
+		var pc uintptr
+		if len(pcs) != 0 {
+			pc = pcs[0]
+		}
+		r := slog.NewRecord(record.Time, record.Level.SlogLevel(), record.Message, pc)
+		r.AddAttrs(slogAttrs(record.Attrs)...)
+		r.AddAttrs(sourceAttrs(record)...)
+		if err := c.handler.Handle(ctx, r); err != nil {
+			fmt.Printf("Error handling log message: %v\n", err)
+		}
 		return
 	}
-	logMsg := defaultLogger.formatter.Format(time.Now(), level, fmt.Sprintf(msg, args...))
-	_, err := defaultLogger.Write([]byte(logMsg))
+
+	record.Attrs = c.prefixed(record.Attrs)
+	if len(c.attrs) != 0 {
+		record.Attrs = append(append(make([]Attr, 0, len(c.attrs)+len(record.Attrs)), c.attrs...), record.Attrs...)
+	}
+	logMsg := c.formatter.Format(record) + "\n"
+	_, err := c.Write([]byte(logMsg))
 	if err != nil {
 		fmt.Printf("Error writing log message: %v\n", err)
 	}
 }
 
+// Log logs a message with the given log level and attributes
+// args are alternating keys and values or Attrs
+//
+//	logger.Log(ctx, logger.InfoLevel, "request", "user", id, logger.Duration("latency", d))
+func (c *Logger) Log(ctx context.Context, level LogLevel, msg string, args ...any) {
+	c.log(ctx, level, msg, args, false)
+}
+
+// Logf logs a formatted message with the given log level
+func (c *Logger) Logf(level LogLevel, msg string, args ...any) {
+	c.log(c.ctx, level, msg, args, true)
+}
+
+// Verbose logs a message with the verbose log level and attributes
+func (c *Logger) Verbose(ctx context.Context, msg string, args ...any) {
+	c.log(ctx, VerboseLevel, msg, args, false)
+}
+
+// Verbosef logs a formatted message with the verbose log level
+func (c *Logger) Verbosef(fmt string, args ...any) {
+	c.log(c.ctx, VerboseLevel, fmt, args, true)
+}
+
+// Debug logs a message with the debug log level and attributes
+func (c *Logger) Debug(ctx context.Context, msg string, args ...any) {
+	c.log(ctx, DebugLevel, msg, args, false)
+}
+
+// Debugf logs a formatted message with the debug log level
+func (c *Logger) Debugf(fmt string, args ...any) {
+	c.log(c.ctx, DebugLevel, fmt, args, true)
+}
+
+// Info logs a message with the info log level and attributes
+func (c *Logger) Info(ctx context.Context, msg string, args ...any) {
+	c.log(ctx, InfoLevel, msg, args, false)
+}
+
+// Infof logs a formatted message with the info log level
+func (c *Logger) Infof(fmt string, args ...any) {
+	c.log(c.ctx, InfoLevel, fmt, args, true)
+}
+
+// Warn logs a message with the warn log level and attributes
+func (c *Logger) Warn(ctx context.Context, msg string, args ...any) {
+	c.log(ctx, WarnLevel, msg, args, false)
+}
+
+// Warnf logs a formatted message with the warn log level
+func (c *Logger) Warnf(fmt string, args ...any) {
+	c.log(c.ctx, WarnLevel, fmt, args, true)
+}
+
+// Error logs a message with the error log level and attributes
+func (c *Logger) Error(ctx context.Context, msg string, args ...any) {
+	c.log(ctx, ErrorLevel, msg, args, false)
+}
+
+// Errorf logs a formatted message with the error log level
+func (c *Logger) Errorf(fmt string, args ...any) {
+	c.log(c.ctx, ErrorLevel, fmt, args, true)
+}
+
+// Panic logs a message with the panic log level and attributes and raises a panic
+func (c *Logger) Panic(ctx context.Context, msg string, args ...any) {
+	c.log(ctx, PanicLevel, msg, args, false)
+	panic(msg)
+}
+
+// Panicf logs a formatted message with the panic log level and raises a panic
+func (c *Logger) Panicf(fmtStr string, args ...any) {
+	c.log(c.ctx, PanicLevel, fmtStr, args, true)
+	panic(fmt.Sprintf(fmtStr, args...))
+}
+
+// With returns a child of the default logger that adds the attributes to every message
+func With(args ...any) *Logger {
+	return defaultLogger.With(args...)
+}
+
+// Log logs a message with the given log level and attributes
+// args are alternating keys and values or Attrs
+func Log(ctx context.Context, level LogLevel, msg string, args ...any) {
+	defaultLogger.log(ctx, level, msg, args, false)
+}
+
 // SetLogLevel sets the log level of the default logger
 func SetLogLevel(level LogLevel) {
 	defaultLogger.SetLogLevel(level)
@@ -220,74 +475,99 @@ func SetFormatter(formatter LogFormatter) {
 	defaultLogger.SetFormatter(formatter)
 }
 
+// SetHandler sends messages of the default logger to the slog handler
+func SetHandler(handler slog.Handler) {
+	defaultLogger.SetHandler(handler)
+}
+
+// SetCaller records the caller of the logging functions of the default logger
+func SetCaller(enabled bool) {
+	defaultLogger.SetCaller(enabled)
+}
+
+// SetCallerSkip skips more frames to find the caller of the default logger
+func SetCallerSkip(skip int) {
+	defaultLogger.SetCallerSkip(skip)
+}
+
+// SetGoroutineID records the id of the goroutine that logs with the default logger
+func SetGoroutineID(enabled bool) {
+	defaultLogger.SetGoroutineID(enabled)
+}
+
+// SetStackTrace records the stack trace for error and panic messages of the default logger
+func SetStackTrace(enabled bool) {
+	defaultLogger.SetStackTrace(enabled)
+}
+
 // SetWriter sets the writer of the default logger
 func SetWriter(wr io.Writer) {
 	defaultLogger.SetWriter(wr)
 }
 
-// Logf logs a message with the given log level
+// Logf logs a formatted message with the given log level
 func Logf(level LogLevel, msg string, args ...any) {
-	Log(defaultLogger.ctx, level, msg, args...)
+	defaultLogger.log(defaultLogger.ctx, level, msg, args, true)
 }
 
-// Verbose logs a message with the verbose log level
-func Verbose(ctx context.Context, fmt string, args ...interface{}) {
-	Log(ctx, VerboseLevel, fmt, args...)
+// Verbose logs a message with the verbose log level and attributes
+func Verbose(ctx context.Context, msg string, args ...interface{}) {
+	defaultLogger.log(ctx, VerboseLevel, msg, args, false)
 }
 
 // Verbose logs a message with the verbose log level
 func Verbosef(fmt string, args ...interface{}) {
-	Logf(VerboseLevel, fmt, args...)
+	defaultLogger.log(defaultLogger.ctx, VerboseLevel, fmt, args, true)
 }
 
-// Debug logs a message with the debug log level
-func Debug(ctx context.Context, fmt string, args ...interface{}) {
-	Log(ctx, DebugLevel, fmt, args...)
+// Debug logs a message with the debug log level and attributes
+func Debug(ctx context.Context, msg string, args ...interface{}) {
+	defaultLogger.log(ctx, DebugLevel, msg, args, false)
 }
 
 // Debug logs a message with the debug log level
 func Debugf(fmt string, args ...interface{}) {
-	Logf(DebugLevel, fmt, args...)
+	defaultLogger.log(defaultLogger.ctx, DebugLevel, fmt, args, true)
 }
 
-// Info logs a message with the info log level
-func Info(ctx context.Context, fmt string, args ...interface{}) {
-	Log(ctx, InfoLevel, fmt, args...)
+// Info logs a message with the info log level and attributes
+func Info(ctx context.Context, msg string, args ...interface{}) {
+	defaultLogger.log(ctx, InfoLevel, msg, args, false)
 }
 
 // Info logs a message with the info log level
 func Infof(fmt string, args ...interface{}) {
-	Logf(InfoLevel, fmt, args...)
+	defaultLogger.log(defaultLogger.ctx, InfoLevel, fmt, args, true)
 }
 
-// Warn logs a message with the warn log level
-func Warn(ctx context.Context, fmt string, args ...interface{}) {
-	Log(ctx, WarnLevel, fmt, args...)
+// Warn logs a message with the warn log level and attributes
+func Warn(ctx context.Context, msg string, args ...interface{}) {
+	defaultLogger.log(ctx, WarnLevel, msg, args, false)
 }
 
 // Warn logs a message with the warn log level
 func Warnf(fmt string, args ...interface{}) {
-	Logf(WarnLevel, fmt, args...)
+	defaultLogger.log(defaultLogger.ctx, WarnLevel, fmt, args, true)
 }
 
-// Error logs a message with the error log level
-func Error(ctx context.Context, fmt string, args ...interface{}) {
-	Log(ctx, ErrorLevel, fmt, args...)
+// Error logs a message with the error log level and attributes
+func Error(ctx context.Context, msg string, args ...interface{}) {
+	defaultLogger.log(ctx, ErrorLevel, msg, args, false)
 }
 
 // Error logs a message with the error log level
 func Errorf(fmt string, args ...interface{}) {
-	Logf(ErrorLevel, fmt, args...)
+	defaultLogger.log(defaultLogger.ctx, ErrorLevel, fmt, args, true)
 }
 
-// Panic logs a message with the panic log level and raises a panic
-func Panic(ctx context.Context, fmtStr string, args ...interface{}) {
-	Log(ctx, PanicLevel, fmtStr, args...)
-	panic(fmt.Sprintf(fmtStr, args...))
+// Panic logs a message with the panic log level and attributes and raises a panic
+func Panic(ctx context.Context, msg string, args ...interface{}) {
+	defaultLogger.log(ctx, PanicLevel, msg, args, false)
+	panic(msg)
 }
 
 // Panic logs a message with the panic log level and raises a panic
 func Panicf(fmtStr string, args ...interface{}) {
-	Logf(PanicLevel, fmtStr, args...)
+	defaultLogger.log(defaultLogger.ctx, PanicLevel, fmtStr, args, true)
 	panic(fmt.Sprintf(fmtStr, args...))
 }
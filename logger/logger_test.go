@@ -1,8 +1,12 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLog(t *testing.T) {
@@ -21,8 +25,8 @@ func TestLog(t *testing.T) {
 			args: args{
 				ctx:   context.Background(),
 				level: DebugLevel,
-				msg:   "level: %s\n",
-				args:  []any{"DebugLevel"},
+				msg:   "log",
+				args:  []any{"level", "DebugLevel"},
 			},
 		},
 
@@ -31,8 +35,8 @@ func TestLog(t *testing.T) {
 			args: args{
 				ctx:   context.Background(),
 				level: InfoLevel,
-				msg:   "level: %s\n",
-				args:  []any{"InfoLevel"},
+				msg:   "log",
+				args:  []any{"level", "InfoLevel"},
 			},
 		},
 
@@ -41,8 +45,8 @@ func TestLog(t *testing.T) {
 			args: args{
 				ctx:   context.Background(),
 				level: WarnLevel,
-				msg:   "level: %s\n",
-				args:  []any{"WarnLevel"},
+				msg:   "log",
+				args:  []any{"level", "WarnLevel"},
 			},
 		},
 
@@ -51,8 +55,8 @@ func TestLog(t *testing.T) {
 			args: args{
 				ctx:   context.Background(),
 				level: ErrorLevel,
-				msg:   "level: %s\n",
-				args:  []any{"ErrorLevel"},
+				msg:   "log",
+				args:  []any{"level", "ErrorLevel"},
 			},
 		},
 	}
@@ -62,3 +66,125 @@ func TestLog(t *testing.T) {
 		})
 	}
 }
+
+// recordFormatter keeps the records it formats
+type recordFormatter struct {
+	records []Record
+}
+
+func (f *recordFormatter) Format(record Record) string {
+	f.records = append(f.records, record)
+	return record.Message
+}
+
+func TestLogger_With(t *testing.T) {
+	formatter := &recordFormatter{}
+	var buf bytes.Buffer
+	logger := NewLoggerWithFormatter(&buf, formatter)
+	logger.SetLogLevel(DebugLevel)
+	child := logger.With("service", "api")
+
+	child.Info(context.Background(), "request", "user", 7, Duration("latency", time.Second), "dangling")
+	logger.Infof("done %d\n", 1)
+	child.Verbose(context.Background(), "skipped")
+
+	want := [][]Attr{
+		{{"service", "api"}, {"user", 7}, {"latency", time.Second}, {badKey, "dangling"}},
+		nil,
+	}
+	if len(formatter.records) != len(want) {
+		t.Fatalf("Format() called %d times, want %d", len(formatter.records), len(want))
+	}
+	for i, record := range formatter.records {
+		if !reflect.DeepEqual(record.Attrs, want[i]) {
+			t.Errorf("Record.Attrs got = %v, want %v", record.Attrs, want[i])
+		}
+	}
+	if buf.String() != "request\ndone 1\n" {
+		t.Errorf("Logger output got = %q", buf.String())
+	}
+}
+
+func TestFormatter_Attrs(t *testing.T) {
+	record := Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.UTC),
+		Level:   InfoLevel,
+		Message: "request",
+		Attrs: []Attr{
+			String("user", "a b"),
+			Int("count", 2),
+			Duration("latency", 1500*time.Millisecond),
+			Err(errors.New("failed")),
+			Any("ok", true),
+		},
+	}
+	tests := []struct {
+		name      string
+		formatter LogFormatter
+		want      string
+	}{
+		{
+			name:      "default",
+			formatter: NewDefaultFormatter(),
+			want:      `2024-01-02 03:04:05 INF request user="a b" count=2 latency=1.5s error=failed ok=true`,
+		},
+		{
+			name:      "json",
+			formatter: NewJSONFormatter(),
+			want:      `{"time":"2024-01-02T03:04:05Z","level":"INF","msg":"request","user":"a b","count":2,"latency":"1.5s","error":"failed","ok":true}`,
+		},
+		{
+			name:      "json with keys and unix ms",
+			formatter: NewJSONFormatter(WithTimeKey("ts"), WithLevelKey(""), WithMessageKey("message"), WithTimeFormat(TimeFormatUnixMilli)),
+			want:      `{"ts":1704164645123,"message":"request","user":"a b","count":2,"latency":"1.5s","error":"failed","ok":true}`,
+		},
+		{
+			name:      "logfmt",
+			formatter: NewLogfmtFormatter(),
+			want:      `time=2024-01-02T03:04:05Z level=INF msg=request user="a b" count=2 latency=1.5s error=failed ok=true`,
+		},
+		{
+			name:      "logfmt with RFC3339Nano",
+			formatter: NewLogfmtFormatter(WithTimeFormat(time.RFC3339Nano), WithLevelKey("lvl")),
+			want:      `time=2024-01-02T03:04:05.123Z lvl=INF msg=request user="a b" count=2 latency=1.5s error=failed ok=true`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formatter.Format(record); got != tt.want {
+				t.Errorf("Format() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_Escaping(t *testing.T) {
+	record := Record{
+		Level:   WarnLevel,
+		Message: "say \"hi\"\n<b>",
+		Attrs:   []Attr{String("bad key=", "a=b"), String("empty", ""), String("", "no key")},
+	}
+	tests := []struct {
+		name      string
+		formatter LogFormatter
+		want      string
+	}{
+		{
+			name:      "json",
+			formatter: NewJSONFormatter(WithTimeKey("")),
+			want:      `{"level":"WRN","msg":"say \"hi\"\n<b>","bad key=":"a=b","empty":"","":"no key"}`,
+		},
+		{
+			name:      "logfmt",
+			formatter: NewLogfmtFormatter(WithTimeKey("")),
+			want:      `level=WRN msg="say \"hi\"\n<b>" bad_key_="a=b" empty="" !BADKEY="no key"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formatter.Format(record); got != tt.want {
+				t.Errorf("Format() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLevel returns the slog level of the level
+// VerboseLevel is below slog.LevelDebug and PanicLevel is above slog.LevelError
+func (l LogLevel) SlogLevel() slog.Level {
+	switch l {
+	case VerboseLevel:
+		return slog.LevelDebug - 4
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case PanicLevel:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// FromSlogLevel returns the level of the slog level, the highest level not above it
+func FromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return VerboseLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	case level < slog.LevelError+4:
+		return ErrorLevel
+	default:
+		return PanicLevel
+	}
+}
+
+// Logger is a slog.Handler, so slog and the logger write the same output
+//
+//	slog.SetDefault(slog.New(logger.GetLogger()))
+var _ slog.Handler = (*Logger)(nil)
+
+// Enabled reports whether a message of the slog level is logged
+func (c *Logger) Enabled(ctx context.Context, level slog.Level) bool {
+	if !c.enabled(FromSlogLevel(level)) {
+		return false
+	}
+	return c.handler == nil || c.handler.Enabled(ctx, level)
+}
+
+// Handle logs the slog record, a record of PanicLevel does not raise a panic
+func (c *Logger) Handle(ctx context.Context, r slog.Record) error {
+	level := FromSlogLevel(r.Level)
+	var pcs []uintptr
+	if r.PC != 0 {
+		pcs = slogCallers(r.PC, c.stackEnabled(level))
+	}
+	if c.handler != nil {
+		record := Record{Level: level}
+		c.addSource(&record, pcs)
+		if attrs := sourceAttrs(record); len(attrs) != 0 {
+			r = r.Clone()
+			r.AddAttrs(attrs...)
+		}
+		return c.handler.Handle(ctx, r)
+	}
+	record := Record{
+		Time:    r.Time,
+		Level:   level,
+		Message: r.Message,
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		record.Attrs = appendSlogAttr(record.Attrs, "", attr)
+		return true
+	})
+	c.output(ctx, record, pcs)
+	return nil
+}
+
+// slogCallers returns the program counters from pc of a slog record
+// the stack is looked up for pc if stack, since slog records only the caller
+func slogCallers(pc uintptr, stack bool) []uintptr {
+	if stack {
+		pcs := callers(3)
+		for i := range pcs {
+			if pcs[i] == pc {
+				return pcs[i:]
+			}
+		}
+	}
+	return []uintptr{pc}
+}
+
+func (c *Logger) WithAttrs(attrs []slog.Attr) slog.Handler {
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return c.With(args...)
+}
+
+// WithGroup returns a child logger that qualifies the keys of the attributes with the name
+// the keys are joined with . by the formatter
+func (c *Logger) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return c
+	}
+	child := c.clone()
+	if c.handler != nil {
+		child.handler = c.handler.WithGroup(name)
+	} else {
+		child.group = c.group + name + "."
+	}
+	return child
+}
+
+// appendSlogAttr appends the slog attr with the key prefixed, a group is flattened to its attrs
+func appendSlogAttr(attrs []Attr, prefix string, attr slog.Attr) []Attr {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if len(attr.Key) != 0 {
+			prefix += attr.Key + "."
+		}
+		for _, a := range value.Group() {
+			attrs = appendSlogAttr(attrs, prefix, a)
+		}
+		return attrs
+	}
+	if len(attr.Key) == 0 {
+		return attrs
+	}
+	return append(attrs, Attr{Key: prefix + attr.Key, Value: value.Any()})
+}
+
+func slogAttrs(attrs []Attr) []slog.Attr {
+	result := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		result[i] = slog.Any(attr.Key, attr.Value)
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogLevel_SlogLevel(t *testing.T) {
+	tests := []struct {
+		level LogLevel
+		want  slog.Level
+	}{
+		{VerboseLevel, slog.LevelDebug - 4},
+		{DebugLevel, slog.LevelDebug},
+		{InfoLevel, slog.LevelInfo},
+		{WarnLevel, slog.LevelWarn},
+		{ErrorLevel, slog.LevelError},
+		{PanicLevel, slog.LevelError + 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := tt.level.SlogLevel(); got != tt.want {
+				t.Errorf("SlogLevel() got = %v, want %v", got, tt.want)
+			}
+			if got := FromSlogLevel(tt.want); got != tt.level {
+				t.Errorf("FromSlogLevel() got = %v, want %v", got, tt.level)
+			}
+			if got := FromSlogLevel(tt.want + 1); got != tt.level {
+				t.Errorf("FromSlogLevel() of %v got = %v, want %v", tt.want+1, got, tt.level)
+			}
+		})
+	}
+}
+
+func TestLogger_Handler(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLoggerWithFormatter(&buf, NewJSONFormatter())
+	l.SetLogLevel(InfoLevel)
+
+	s := slog.New(l).With("service", "api").WithGroup("req")
+	s.Debug("skipped")
+	s.Info("request", "id", 7, slog.Group("user", "name", "a"))
+	l.With("service", "api").Warn(context.Background(), "slow")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`"level":"INF","msg":"request","service":"api","req.id":7,"req.user.name":"a"}`,
+		`"level":"WRN","msg":"slow","service":"api"}`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Logger output got = %q", buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, want[i]) {
+			t.Errorf("Logger output got = %s, want suffix %s", line, want[i])
+		}
+	}
+}
+
+func TestLogger_SlogBackend(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug - 4,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	l := NewLoggerWithHandler(handler)
+	l.SetLogLevel(VerboseLevel)
+
+	l.With("service", "api").Info(context.Background(), "request", "id", 7)
+	l.Verbosef("entry %s\n", "f")
+	slog.New(l.WithGroup("g")).Warn("slow", "ms", 10)
+
+	want := "level=INFO msg=request service=api id=7\n" +
+		"level=DEBUG-4 msg=\"entry f\"\n" +
+		"level=WARN msg=slow g.ms=10\n"
+	if buf.String() != want {
+		t.Errorf("Logger output got = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth is the number of frames of a stack trace at most
+const maxStackDepth = 32
+
+// Caller is the location of the call to the logging function
+type Caller struct {
+	File     string
+	Line     int
+	Function string
+}
+
+// IsZero reports whether the caller is not recorded
+func (c Caller) IsZero() bool {
+	return c.Line == 0
+}
+
+// String returns the directory and the name of the file with the line, like logger/logger.go:12
+func (c Caller) String() string {
+	dir, file := filepath.Split(c.File)
+	return filepath.Join(filepath.Base(dir), file) + ":" + strconv.Itoa(c.Line)
+}
+
+func (c *Logger) stackEnabled(level LogLevel) bool {
+	return c.stackTrace && level >= ErrorLevel
+}
+
+// addSource sets the caller, the goroutine id and the stack trace of the record as enabled
+// pcs are the program counters from the caller
+func (c *Logger) addSource(record *Record, pcs []uintptr) {
+	if c.caller && len(pcs) != 0 {
+		frame, _ := runtime.CallersFrames(pcs[:1]).Next()
+		record.Caller = Caller{File: frame.File, Line: frame.Line, Function: frame.Function}
+	}
+	if c.goroutineID {
+		record.GoroutineID = goroutineID()
+	}
+	if c.stackEnabled(record.Level) && len(pcs) != 0 {
+		record.Stack = formatStack(pcs)
+	}
+}
+
+// sourceAttrs returns the goroutine id and the stack trace of the record as attrs for a slog handler
+// the caller is the pc of the slog record
+func sourceAttrs(record Record) []slog.Attr {
+	var attrs []Attr
+	if record.GoroutineID != 0 {
+		attrs = append(attrs, Uint64("goroutine", record.GoroutineID))
+	}
+	if len(record.Stack) != 0 {
+		attrs = append(attrs, String("stack", record.Stack))
+	}
+	return slogAttrs(attrs)
+}
+
+// callers returns the program counters of the stack, skip of them as runtime.Callers
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(skip, pcs)]
+}
+
+// formatStack formats the frames as function and file:line lines
+func formatStack(pcs []uintptr) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// goroutineID returns the id of the current goroutine from the header of its stack
+// like goroutine 1 [running]:
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	header := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+	if i := strings.IndexByte(header, ' '); i > 0 {
+		id, _ := strconv.ParseUint(header[:i], 10, 64)
+		return id
+	}
+	return 0
+}
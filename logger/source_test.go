@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// thisLine returns the line of its caller
+func thisLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+// logWrapper logs through one more frame
+func logWrapper(l *Logger, msg string) {
+	l.Warn(context.Background(), msg)
+}
+
+func TestLogger_Caller(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		skip int
+		// log logs and returns the line of the call
+		log       func(l *Logger) int
+		wantStack bool
+	}{
+		{
+			name: "method",
+			log: func(l *Logger) int {
+				l.Info(ctx, "info")
+				return thisLine() - 1
+			},
+		},
+		{
+			name: "formatted method",
+			log: func(l *Logger) int {
+				l.Errorf("error %d", 1)
+				return thisLine() - 1
+			},
+			wantStack: true,
+		},
+		{
+			name: "package function",
+			log: func(l *Logger) int {
+				Error(ctx, "error")
+				return thisLine() - 1
+			},
+			wantStack: true,
+		},
+		{
+			name: "slog",
+			log: func(l *Logger) int {
+				slog.New(l).Error("error")
+				return thisLine() - 1
+			},
+			wantStack: true,
+		},
+		{
+			name: "wrapper",
+			skip: 1,
+			log: func(l *Logger) int {
+				logWrapper(l, "warn")
+				return thisLine() - 1
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := &recordFormatter{}
+			l := NewLoggerWithFormatter(io.Discard, formatter)
+			l.SetLogLevel(DebugLevel)
+			l.SetCaller(true)
+			l.SetCallerSkip(tt.skip)
+			l.SetGoroutineID(true)
+			l.SetStackTrace(true)
+			saved := defaultLogger
+			defaultLogger = l
+			defer func() { defaultLogger = saved }()
+
+			line := tt.log(l)
+			if len(formatter.records) != 1 {
+				t.Fatalf("Format() called %d times, want 1", len(formatter.records))
+			}
+			record := formatter.records[0]
+			if !strings.HasSuffix(record.Caller.File, "source_test.go") || record.Caller.Line != line ||
+				!strings.Contains(record.Caller.Function, "TestLogger_Caller") {
+				t.Errorf("Record.Caller got = %+v, want source_test.go:%d", record.Caller, line)
+			}
+			if record.GoroutineID == 0 {
+				t.Errorf("Record.GoroutineID got = 0")
+			}
+			if tt.wantStack != (len(record.Stack) != 0) {
+				t.Errorf("Record.Stack got = %q, want stack %v", record.Stack, tt.wantStack)
+			}
+			if tt.wantStack && !strings.HasPrefix(record.Stack, record.Caller.Function+"\n") {
+				t.Errorf("Record.Stack got = %q, want from %s", record.Stack, record.Caller.Function)
+			}
+		})
+	}
+}
+
+func TestFormatter_Source(t *testing.T) {
+	record := Record{
+		Level:       ErrorLevel,
+		Message:     "failed",
+		Attrs:       []Attr{Int("id", 1)},
+		Caller:      Caller{File: "/src/app/main.go", Line: 12, Function: "main.main"},
+		GoroutineID: 7,
+		Stack:       "main.main\n\t/src/app/main.go:12",
+	}
+	tests := []struct {
+		name      string
+		formatter LogFormatter
+		want      string
+	}{
+		{
+			name:      "json",
+			formatter: NewJSONFormatter(WithTimeKey(""), WithCallerKey("source")),
+			want:      `{"level":"ERR","msg":"failed","source":"app/main.go:12","goroutine":7,"id":1,"stack":"main.main\n\t/src/app/main.go:12"}`,
+		},
+		{
+			name:      "logfmt",
+			formatter: NewLogfmtFormatter(WithTimeKey(""), WithStackKey("")),
+			want:      `level=ERR msg=failed caller=app/main.go:12 goroutine=7 id=1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formatter.Format(record); got != tt.want {
+				t.Errorf("Format() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}